@@ -55,7 +55,7 @@ type ClusterSpec struct {
 	// +optional
 
 	// GatewayHost, the Full Qualified Domain Name or IP of the gateway/ingress of this cluster
-	// If it's an IP address, only IPv4 is supported
+	// It can be an IPv4 or IPv6 address, a domain name resolving to both families makes the gateway dual-stack
 	GatewayHost string `json:"gatewayHost,omitempty"`
 
 	// +kubebuilder:default=80
@@ -66,16 +66,37 @@ type ClusterSpec struct {
 	// The port number of the gateway
 	GatewayPort int32 `json:"gatewayPort,omitempty"`
 
-	// FIXME: temp solution, should NOT store this as plain text.
-	//  consider use cli to add cluster to control plane, import kubeconfig
-	//  and create a Secret with proper SA to store it as bytes
-
 	// +optional
 
 	// Kubeconfig, The kubeconfig of the cluster you want to connnect to
 	// This's not needed if ClusterMode is InCluster, it will use InCluster
 	// config
+	// Deprecated: it's stored as plain text, use KubeconfigSecretRef instead
 	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// +optional
+
+	// KubeconfigSecretRef, references the key of a Secret which contains the kubeconfig
+	// of the cluster you want to connect to, it's mutually exclusive with Kubeconfig.
+	// Updating the Secret restarts the connector of the cluster.
+	KubeconfigSecretRef *SecretKeyReference `json:"kubeconfigSecretRef,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret
+type SecretKeyReference struct {
+	// Name of the Secret
+	Name string `json:"name"`
+
+	// +optional
+
+	// Namespace of the Secret, it must be the namespace of ErieCanal if set
+	Namespace string `json:"namespace,omitempty"`
+
+	// +kubebuilder:default=kubeconfig
+	// +optional
+
+	// Key of the kubeconfig in the Secret data
+	Key string `json:"key,omitempty"`
 }
 
 // ClusterStatus defines the observed state of Cluster
@@ -86,6 +107,11 @@ type ClusterStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// +optional
+	// LastProbeTime is the time when the cluster was probed last time,
+	// Ready and GatewayReachable conditions reflect the result of it
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
 }
 
 // ClusterConditionType identifies a specific condition.
@@ -95,6 +121,13 @@ const (
 	// ClusterManaged means that the cluster has joined the CLusterSet successfully
 	//  and is managed by Control Plane.
 	ClusterManaged ClusterConditionType = "Managed"
+
+	// ClusterReady means that the API server of the cluster is reachable from Control Plane.
+	ClusterReady ClusterConditionType = "Ready"
+
+	// ClusterGatewayReachable means that the gateway of the cluster(GatewayHost:GatewayPort)
+	//  is reachable from Control Plane.
+	ClusterGatewayReachable ClusterConditionType = "GatewayReachable"
 )
 
 // +genclient
@@ -111,6 +144,8 @@ const (
 // +kubebuilder:printcolumn:name="Gateway Port",type="integer",priority=0,JSONPath=".spec.gatewayPort"
 // +kubebuilder:printcolumn:name="Managed",type="string",priority=0,JSONPath=".status.conditions[?(@.type=='Managed')].status"
 // +kubebuilder:printcolumn:name="Managed Age",type="date",priority=0,JSONPath=".status.conditions[?(@.type=='Managed')].lastTransitionTime"
+// +kubebuilder:printcolumn:name="Ready",type="string",priority=0,JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Gateway Reachable",type="string",priority=1,JSONPath=".status.conditions[?(@.type=='GatewayReachable')].status"
 // +kubebuilder:printcolumn:name="Age",type="date",priority=0,JSONPath=".metadata.creationTimestamp"
 
 // Cluster is the Schema for the clusters API
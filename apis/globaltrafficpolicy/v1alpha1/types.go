@@ -26,11 +26,17 @@ const (
 	FailOverLbType     LoadBalancerType = "FailOver"
 )
 
+// DefaultWeight is the weight of an ActiveActive target which has no weight, and of the local cluster
+// if it's not one of the targets
+const DefaultWeight = 100
+
 type TrafficTarget struct {
 	// Format: [region]/[zone]/[group]/[cluster]
 	ClusterKey string `json:"clusterKey"`
 
 	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// Weight is the relative weight of the cluster in ActiveActive, it defaults to 100
 	Weight *int `json:"weight,omitempty"`
 }
 
@@ -43,12 +49,80 @@ type GlobalTrafficPolicySpec struct {
 
 	// +optional
 	Targets []TrafficTarget `json:"targets,omitempty"`
+
+	// +optional
+	// LocalityThresholds are the minimum numbers of available clusters of locality tiers,
+	// it takes effect only if LbType is Locality and no target is specified
+	LocalityThresholds *LocalityThresholds `json:"localityThresholds,omitempty"`
+}
+
+// LocalityThresholds configures when traffic spills over to the next locality tier. The tiers are the
+// local cluster, then clusters in the same group, zone and region of it, at last all other clusters.
+// A tier takes the traffic only if it has at least the number of available clusters, each defaults to 1.
+type LocalityThresholds struct {
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// Clusters in the same region/zone/group
+	Group *int `json:"group,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// Clusters in the same region/zone
+	Zone *int `json:"zone,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// Clusters in the same region
+	Region *int `json:"region,omitempty"`
 }
 
 // GlobalTrafficPolicyStatus defines the observed state of GlobalTrafficPolicy
 type GlobalTrafficPolicyStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// +optional
+	// +listType=map
+	// +listMapKey=clusterKey
+	// Targets are the clusters resolved from the policy, including the ones
+	// listed in spec and the ones traffic is actually routed to
+	Targets []TrafficTargetStatus `json:"targets,omitempty"`
 }
 
+type TrafficTargetStatus struct {
+	// Format: [region]/[zone]/[group]/[cluster]
+	ClusterKey string `json:"clusterKey"`
+
+	// Reachable is true if the cluster has endpoints of the service
+	Reachable bool `json:"reachable"`
+
+	// Active is true if traffic is routed to the cluster
+	Active bool `json:"active"`
+
+	// +optional
+	// Weight is the effective weight of an active target, it's empty if the
+	// traffic is not weighted
+	Weight *int `json:"weight,omitempty"`
+}
+
+// GlobalTrafficPolicyConditionType identifies a specific condition.
+type GlobalTrafficPolicyConditionType string
+
+const (
+	// GlobalTrafficPolicyAccepted means the policy is valid and has been accepted by controller.
+	GlobalTrafficPolicyAccepted GlobalTrafficPolicyConditionType = "Accepted"
+	// GlobalTrafficPolicyResolvedTargets means all cluster keys in targets refer to
+	// known clusters, the message contains the unresolved ones if it's "False".
+	GlobalTrafficPolicyResolvedTargets GlobalTrafficPolicyConditionType = "ResolvedTargets"
+	// GlobalTrafficPolicyProgrammed means the policy is applied to the ServiceImport
+	// with the same namespace/name, and there's at least one active target.
+	GlobalTrafficPolicyProgrammed GlobalTrafficPolicyConditionType = "Programmed"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
@@ -56,6 +130,7 @@ type GlobalTrafficPolicyStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=gtp,scope=Namespaced
 // +kubebuilder:printcolumn:name="LB Type",type="string",priority=0,JSONPath=".spec.lbType"
+// +kubebuilder:printcolumn:name="Programmed",type="string",priority=0,JSONPath=".status.conditions[?(@.type=='Programmed')].status"
 // +kubebuilder:printcolumn:name="Age",type="date",priority=0,JSONPath=".metadata.creationTimestamp"
 
 // GlobalTrafficPolicy is the Schema for the GlobalTrafficPolicys API
@@ -20,28 +20,74 @@ import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 // MultiClusterEndpointSpec defines the desired state of MultiClusterEndpoint
 type MultiClusterEndpointSpec struct {
+	// The name of ServiceImport in the same namespace which the endpoint is attached to
+	ServiceName string `json:"serviceName"`
+
+	// The port of ServiceImport which the endpoint serves
+	Port int32 `json:"port"`
+
+	// ClusterKey identifies where the endpoint lives, in format [region]/[zone]/[group]/[cluster].
+	// It must not be the key of a cluster in the ClusterSet, as endpoints of a cluster are managed
+	// by its ServiceExports
 	ClusterKey string `json:"clusterKey"`
 
 	Target Target `json:"target"`
 }
 
 type Target struct {
+	// +optional
+	// Host of the endpoint, IP is used if it's empty
 	Host string `json:"host"`
 	IP   string `json:"ip"`
-	Port int32  `json:"port"`
+
+	// +optional
+	// IPs of all IP families if the endpoint is dual-stack, IP is the first of them
+	IPs []string `json:"ips,omitempty"`
+
+	Port int32 `json:"port"`
+	// +optional
 	Path string `json:"path"`
 }
 
 // MultiClusterEndpointStatus defines the observed state of MultiClusterEndpoint
 type MultiClusterEndpointStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// +optional
+	// Attached is the endpoint which has been merged into the ServiceImport, it's detached
+	// when the spec is changed or the MultiClusterEndpoint is deleted
+	Attached *AttachedEndpoint `json:"attached,omitempty"`
 }
 
+type AttachedEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	Port        int32  `json:"port"`
+	ClusterKey  string `json:"clusterKey"`
+	Target      Target `json:"target"`
+}
+
+// MultiClusterEndpointConditionType identifies a specific condition.
+type MultiClusterEndpointConditionType string
+
+const (
+	// MultiClusterEndpointAttached means the endpoint has been merged into the ServiceImport
+	MultiClusterEndpointAttached MultiClusterEndpointConditionType = "Attached"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:resource:shortName=mce,scope=Namespaced
+// +kubebuilder:printcolumn:name="Service",type="string",priority=0,JSONPath=".spec.serviceName"
+// +kubebuilder:printcolumn:name="Port",type="integer",priority=0,JSONPath=".spec.port"
+// +kubebuilder:printcolumn:name="Attached",type="string",priority=0,JSONPath=".status.conditions[?(@.type=='Attached')].status"
 // +kubebuilder:printcolumn:name="Age",type="date",priority=0,JSONPath=".metadata.creationTimestamp"
 
 // MultiClusterEndpoint is the Schema for the MultiClusterEndpoints API
@@ -22,16 +22,33 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttachedEndpoint) DeepCopyInto(out *AttachedEndpoint) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttachedEndpoint.
+func (in *AttachedEndpoint) DeepCopy() *AttachedEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(AttachedEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiClusterEndpoint) DeepCopyInto(out *MultiClusterEndpoint) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterEndpoint.
@@ -87,7 +104,7 @@ func (in *MultiClusterEndpointList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiClusterEndpointSpec) DeepCopyInto(out *MultiClusterEndpointSpec) {
 	*out = *in
-	out.Target = in.Target
+	in.Target.DeepCopyInto(&out.Target)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterEndpointSpec.
@@ -103,6 +120,18 @@ func (in *MultiClusterEndpointSpec) DeepCopy() *MultiClusterEndpointSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiClusterEndpointStatus) DeepCopyInto(out *MultiClusterEndpointStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Attached != nil {
+		in, out := &in.Attached, &out.Attached
+		*out = new(AttachedEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterEndpointStatus.
@@ -118,6 +147,11 @@ func (in *MultiClusterEndpointStatus) DeepCopy() *MultiClusterEndpointStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Target) DeepCopyInto(out *Target) {
 	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
@@ -17,9 +17,12 @@
 package v1alpha1
 
 import (
+	"fmt"
 	"github.com/flomesh-io/ErieCanal/pkg/route"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
 )
 
 type ServiceExportRule struct {
@@ -44,9 +47,26 @@ type ServiceExportRule struct {
 	//   matches /foo/bar/baz, but does not match /foo/barbaz).
 
 	// +kubebuilder:validation:Enum=Exact;Prefix
-	PathType *networkingv1.PathType `json:"pathType"`
+	// +optional
+	// PathType is required in HTTP mode, it's ignored in L4 mode
+	PathType *networkingv1.PathType `json:"pathType,omitempty"`
+
+	// +kubebuilder:validation:Enum=TCP;UDP
+	// +optional
+	// Protocol of the service port, ONLY for L4 mode, defaults to TCP
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
 }
 
+// ServiceExportMode is the way how the exported service is accessed via the gateway of exporting cluster
+type ServiceExportMode string
+
+const (
+	// ServiceExportModeHTTP routes HTTP requests to the service by path
+	ServiceExportModeHTTP ServiceExportMode = "HTTP"
+	// ServiceExportModeL4 proxies TCP/UDP traffic of a dedicated gateway port to each exported service port
+	ServiceExportModeL4 ServiceExportMode = "L4"
+)
+
 type PathRewrite struct {
 	From string `json:"from,omitempty"`
 	To   string `json:"to,omitempty"`
@@ -68,6 +88,13 @@ type ServiceExportSpec struct {
 	// The LoadBalancer Type applied to the Ingress Rules those created by the ServiceExport
 	LoadBalancer route.AlgoBalancer `json:"loadBalancer,omitempty"`
 
+	// +kubebuilder:default=HTTP
+	// +kubebuilder:validation:Enum=HTTP;L4
+	// +optional
+	// Mode of the export, in L4 mode a dedicated gateway port is allocated for each rule
+	// and the Path/PathType of rules are ignored
+	Mode ServiceExportMode `json:"mode,omitempty"`
+
 	// +kubebuilder:validation:MinItems=1
 	// The paths for accessing the service via Ingress controller
 	Rules []ServiceExportRule `json:"rules,omitempty"`
@@ -75,7 +102,8 @@ type ServiceExportSpec struct {
 	// +optional
 	// If empty, service is exported to all managed clusters.
 	// If not empty, service is exported to specified clusters,
-	//  must be in format [region]/[zone]/[group]/[cluster]
+	//  must be in format [region]/[zone]/[group]/[cluster],
+	//  each segment can be a wildcard '*', e.g. us-east/*/*/*
 	TargetClusters []string `json:"targetClusters,omitempty"`
 
 	// +optional
@@ -91,6 +119,49 @@ type ServiceExportStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// +optional
+	// Hostnames of the Pods backing a headless Service, each of them is routed
+	// individually by the gateway of exporting cluster
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// +optional
+	// Gateway ports allocated for the exported ports in L4 mode
+	GatewayPorts []ServiceExportGatewayPort `json:"gatewayPorts,omitempty"`
+
+	// +optional
+	// +listType=map
+	// +listMapKey=clusterKey
+	// Import status of the service in each target cluster, reported by the connectors
+	Clusters []ServiceExportClusterStatus `json:"clusters,omitempty"`
+}
+
+// ServiceExportClusterStatus is the import status of a ServiceExport in a cluster of the ClusterSet
+type ServiceExportClusterStatus struct {
+	// The key of the importing cluster
+	ClusterKey string `json:"clusterKey"`
+
+	// Whether the service is imported by the cluster
+	Imported bool `json:"imported"`
+
+	// +optional
+	// The last error of importing the service, empty if it's imported successfully
+	LastError string `json:"lastError,omitempty"`
+
+	// The last time the status was reported by the connector of the cluster
+	ObservedTime metav1.Time `json:"observedTime"`
+}
+
+// ServiceExportGatewayPort is the dedicated port of gateway which proxies the traffic to a service port
+type ServiceExportGatewayPort struct {
+	// The port number of service
+	PortNumber int32 `json:"portNumber"`
+
+	// Protocol of the service port
+	Protocol corev1.Protocol `json:"protocol"`
+
+	// The port number of gateway
+	GatewayPort int32 `json:"gatewayPort"`
 }
 
 // ServiceExportConditionType identifies a specific condition.
@@ -119,6 +190,8 @@ const (
 // +kubebuilder:resource:shortName=sexp,scope=Namespaced
 // +kubebuilder:printcolumn:name="Valid",type="string",priority=0,JSONPath=".status.conditions[?(@.type=='Valid')].status"
 // +kubebuilder:printcolumn:name="Conflict",type="string",priority=0,JSONPath=".status.conditions[?(@.type=='Conflict')].status"
+// +kubebuilder:printcolumn:name="Imported",type="string",priority=1,JSONPath=".status.clusters[?(@.imported==true)].clusterKey"
+// +kubebuilder:printcolumn:name="Mode",type="string",priority=1,JSONPath=".spec.mode"
 // +kubebuilder:printcolumn:name="Age",type="date",priority=0,JSONPath=".metadata.creationTimestamp"
 
 // ServiceExport is the Schema for the ServiceExports API
@@ -147,3 +220,81 @@ type ServiceExportList struct {
 func init() {
 	SchemeBuilder.Register(&ServiceExport{}, &ServiceExportList{})
 }
+
+const (
+	clusterKeySegments   = 4
+	clusterKeySeparator  = "/"
+	clusterKeySegmentAny = "*"
+)
+
+// IsTargetCluster returns true if the service is exported to the cluster with the key
+func (s *ServiceExport) IsTargetCluster(clusterKey string) bool {
+	if len(s.Spec.TargetClusters) == 0 {
+		return true
+	}
+
+	for _, target := range s.Spec.TargetClusters {
+		if matchClusterKey(target, clusterKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsL4 returns true if the service is exported in L4 mode
+func (s *ServiceExport) IsL4() bool {
+	return s.Spec.Mode == ServiceExportModeL4
+}
+
+// GatewayPort returns the gateway port allocated for the service port, 0 if it's not allocated yet
+func (s *ServiceExport) GatewayPort(portNumber int32, protocol corev1.Protocol) int32 {
+	for _, p := range s.Status.GatewayPorts {
+		if p.PortNumber == portNumber && p.Protocol == protocol {
+			return p.GatewayPort
+		}
+	}
+
+	return 0
+}
+
+// GetProtocol returns the protocol of the rule, defaults to TCP
+func (r ServiceExportRule) GetProtocol() corev1.Protocol {
+	if r.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+
+	return r.Protocol
+}
+
+func matchClusterKey(pattern, clusterKey string) bool {
+	patternSegments := strings.Split(pattern, clusterKeySeparator)
+	keySegments := strings.Split(clusterKey, clusterKeySeparator)
+	if len(patternSegments) != clusterKeySegments || len(keySegments) != clusterKeySegments {
+		return false
+	}
+
+	for i := range patternSegments {
+		if patternSegments[i] != clusterKeySegmentAny && patternSegments[i] != keySegments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateTargetCluster checks if the target cluster is in format [region]/[zone]/[group]/[cluster]
+func ValidateTargetCluster(target string) error {
+	segments := strings.Split(target, clusterKeySeparator)
+	if len(segments) != clusterKeySegments {
+		return fmt.Errorf("target cluster %q must be in format [region]/[zone]/[group]/[cluster]", target)
+	}
+
+	for _, seg := range segments {
+		if seg == "" {
+			return fmt.Errorf("target cluster %q has empty segment, use '*' to match any", target)
+		}
+	}
+
+	return nil
+}
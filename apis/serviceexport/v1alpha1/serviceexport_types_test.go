@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestIsTargetCluster(t *testing.T) {
+	testCases := []struct {
+		name       string
+		targets    []string
+		clusterKey string
+		expected   bool
+	}{
+		{
+			name:       "no target clusters exports to all clusters",
+			clusterKey: "default/default/default/cluster1",
+			expected:   true,
+		},
+		{
+			name:       "exact cluster key",
+			targets:    []string{"default/default/default/cluster1"},
+			clusterKey: "default/default/default/cluster1",
+			expected:   true,
+		},
+		{
+			name:       "another cluster key",
+			targets:    []string{"default/default/default/cluster1"},
+			clusterKey: "default/default/default/cluster2",
+			expected:   false,
+		},
+		{
+			name:       "wildcard cluster",
+			targets:    []string{"us/east/prod/*"},
+			clusterKey: "us/east/prod/cluster1",
+			expected:   true,
+		},
+		{
+			name:       "wildcard zone and cluster",
+			targets:    []string{"us/*/prod/*"},
+			clusterKey: "us/west/prod/cluster2",
+			expected:   true,
+		},
+		{
+			name:       "wildcard doesn't match the other segments",
+			targets:    []string{"us/*/prod/*"},
+			clusterKey: "us/west/dev/cluster2",
+			expected:   false,
+		},
+		{
+			name:       "wildcard matches a whole segment only",
+			targets:    []string{"us/east/prod/cluster*"},
+			clusterKey: "us/east/prod/cluster1",
+			expected:   false,
+		},
+		{
+			name:       "any of the targets",
+			targets:    []string{"eu/*/*/*", "us/east/prod/cluster1"},
+			clusterKey: "us/east/prod/cluster1",
+			expected:   true,
+		},
+		{
+			name:       "pattern with fewer segments",
+			targets:    []string{"us/*/*"},
+			clusterKey: "us/east/prod/cluster1",
+			expected:   false,
+		},
+		{
+			name:       "cluster key with fewer segments",
+			targets:    []string{"*/*/*/*"},
+			clusterKey: "east/prod/cluster1",
+			expected:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			export := &ServiceExport{Spec: ServiceExportSpec{TargetClusters: tc.targets}}
+			if actual := export.IsTargetCluster(tc.clusterKey); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -69,6 +69,37 @@ func (in *ServiceExport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceExportClusterStatus) DeepCopyInto(out *ServiceExportClusterStatus) {
+	*out = *in
+	in.ObservedTime.DeepCopyInto(&out.ObservedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportClusterStatus.
+func (in *ServiceExportClusterStatus) DeepCopy() *ServiceExportClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceExportClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceExportGatewayPort) DeepCopyInto(out *ServiceExportGatewayPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportGatewayPort.
+func (in *ServiceExportGatewayPort) DeepCopy() *ServiceExportGatewayPort {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceExportGatewayPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceExportList) DeepCopyInto(out *ServiceExportList) {
 	*out = *in
@@ -163,6 +194,23 @@ func (in *ServiceExportStatus) DeepCopyInto(out *ServiceExportStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GatewayPorts != nil {
+		in, out := &in.GatewayPorts, &out.GatewayPorts
+		*out = make([]ServiceExportGatewayPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ServiceExportClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportStatus.
@@ -18,9 +18,14 @@ package v1alpha1
 
 import (
 	"fmt"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	"hash/fnv"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	utilnet "k8s.io/utils/net"
 	"strings"
+	"time"
 )
 
 // ServiceImportType designates the type of a ServiceImport
@@ -115,13 +120,36 @@ func (p *ServicePort) String() string {
 type Endpoint struct {
 	Target     Target `json:"target"`
 	ClusterKey string `json:"clusterKey"`
+
+	// +optional
+	// Hostname of the Pod in exporting cluster, ONLY for Headless ServiceImport.
+	// Requests to the Pod are sent to Target with host PodHost(Hostname, ...)
+	Hostname string `json:"hostname,omitempty"`
 }
 
 type Target struct {
 	Host string `json:"host"`
 	IP   string `json:"ip"`
+
+	// +optional
+	// Gateway IPs of all IP families if the exporting cluster is dual-stack, IP is the first of them
+	IPs []string `json:"ips,omitempty"`
+
 	Port int32  `json:"port"`
 	Path string `json:"path"`
+
+	// +optional
+	// TLS is set if the gateway of exporting cluster only accepts mTLS traffic from other clusters
+	TLS *TargetTLS `json:"tls,omitempty"`
+}
+
+// TargetTLS is how the gateway of exporting cluster is verified, it presents a certificate
+// which is issued for SNI by CA
+type TargetTLS struct {
+	// SNI is the server name of the gateway, it identifies the exporting cluster
+	SNI string `json:"sni"`
+	// CA is the PEM encoded CA certificate which the certificate of gateway is issued by
+	CA string `json:"ca"`
 }
 
 // ServiceImportStatus describes derived state of an imported service.
@@ -146,6 +174,34 @@ type ClusterStatus struct {
 	// otherwise, it's the url of accessing that service in remote cluster
 	// for example, http(s)://[Ingress IP/domain name]:[port]/[path]
 	Addresses []string `json:"addresses,omitempty"`
+
+	// +optional
+	// Unhealthy is set if the gateway of the cluster isn't reachable by the prober of control plane,
+	// traffic isn't routed to the cluster until it recovers
+	Unhealthy bool `json:"unhealthy,omitempty"`
+
+	// +optional
+	// Ejection is the last time the cluster is ejected by outlier detection
+	Ejection *ClusterEjection `json:"ejection,omitempty"`
+}
+
+// ClusterEjection records that traffic to the service isn't routed to the cluster for a while, as the
+// gateway of it keeps failing
+type ClusterEjection struct {
+	// The cluster is ejected until the time, it's back once the time passes
+	EjectedUntil metav1.Time `json:"ejectedUntil"`
+
+	// Ejections is the number of consecutive ejections, the ejection time doubles with each one
+	Ejections int32 `json:"ejections"`
+
+	// +optional
+	// Reason is a human-readable message indicating why the cluster is ejected
+	Reason string `json:"reason,omitempty"`
+}
+
+// IsEjected returns true if the cluster is ejected at the time
+func (e *ClusterEjection) IsEjected(now time.Time) bool {
+	return e != nil && now.Before(e.EjectedUntil.Time)
 }
 
 // +genclient
@@ -182,3 +238,62 @@ type ServiceImportList struct {
 func init() {
 	SchemeBuilder.Register(&ServiceImport{}, &ServiceImportList{})
 }
+
+// PodHost is the host by which the gateway of exporting cluster routes requests to a single Pod
+// of a headless Service, e.g. web-0.nginx.default.svc.clusterset.local
+func PodHost(hostname, name, namespace string) string {
+	return fmt.Sprintf("%s.%s.%s.svc.%s", hostname, name, namespace, commons.ClusterSetDomain)
+}
+
+// ClusterName returns the name of cluster, which is the last segment of the cluster key
+func ClusterName(clusterKey string) string {
+	return clusterKey[strings.LastIndex(clusterKey, "/")+1:]
+}
+
+// DerivedServiceName is the name of the ClusterIP Service which is derived from the ServiceImport
+func (s *ServiceImport) DerivedServiceName() string {
+	return DerivedServiceName(s.Name)
+}
+
+// DerivedServiceName prefixes the name of ServiceImport with derived-, if it exceeds the 63 characters
+// limit of Service name, it's truncated and suffixed with the hash of the full name to keep it unique
+func DerivedServiceName(name string) string {
+	derived := commons.DerivedServicePrefix + name
+	if len(derived) <= validation.DNS1035LabelMaxLength {
+		return derived
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	suffix := fmt.Sprintf("-%08x", h.Sum32())
+
+	return strings.TrimRight(derived[:validation.DNS1035LabelMaxLength-len(suffix)], "-") + suffix
+}
+
+// AllIPs returns the gateway IPs of all IP families
+func (t Target) AllIPs() []string {
+	if len(t.IPs) > 0 {
+		return t.IPs
+	}
+
+	if t.IP == "" {
+		return nil
+	}
+
+	return []string{t.IP}
+}
+
+// IPOfFamily returns the gateway IP of the IP family, it's empty if the exporting cluster
+// has no address of the family
+func (t Target) IPOfFamily(family v1.IPFamily) string {
+	for _, ip := range t.AllIPs() {
+		switch {
+		case family == v1.IPv4Protocol && utilnet.IsIPv4String(ip):
+			return ip
+		case family == v1.IPv6Protocol && utilnet.IsIPv6String(ip):
+			return ip
+		}
+	}
+
+	return ""
+}
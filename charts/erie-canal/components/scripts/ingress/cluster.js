@@ -0,0 +1,39 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+((
+  ingress = pipy.solve('ingress.js'),
+
+  // the root CA of control plane, gateway certificates are issued by it or the intermediate CAs of clusters
+  rootCA = ingress?.gatewayTLS?.certificate?.ca || ingress?.egress?.certificate?.ca,
+  caName = rootCA ? (new crypto.Certificate(rootCA)?.subject?.commonName || '').toLowerCase() : '',
+
+  namesOf = (cert) => (
+    [cert?.subject?.commonName, ...(cert?.subjectAltNames || [])].filter(Boolean).map(n => n.toLowerCase())
+  ),
+
+) => ({
+  namesOf,
+
+  // the intermediate CAs of clusters are named after the root CA, e.g. default.default.default.cluster1.flomesh.io
+  isCA: (cert) => (
+    Boolean(caName) && namesOf(cert).some(n => n === caName || n.endsWith(`.${caName}`))
+  ),
+
+  // a gateway certificate identifies the cluster by <reversed cluster key>.gateway.clusterset.local
+  gatewayOf: (cert) => (
+    namesOf(cert).find(n => n.endsWith('.gateway.clusterset.local'))
+  ),
+}))()
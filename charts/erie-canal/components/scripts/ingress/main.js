@@ -20,10 +20,60 @@
     issuingCAs
   } = pipy.solve('config.js'),
 
+  ingress = pipy.solve('ingress.js'),
+
+  cluster = pipy.solve('cluster.js'),
+
+  balancers = {
+    'round-robin': algo.RoundRobinLoadBalancer,
+    'least-work': algo.LeastWorkLoadBalancer,
+    'hashing': algo.HashingLoadBalancer,
+  },
+
+  l4Proxies = (
+    Object.fromEntries(
+      Object.entries(ingress?.l4 || {}).map(
+        ([port, v]) => (
+          ((targets, balancer) => (
+            targets = v?.upstream?.endpoints?.map?.(ep => `${ep.ip}:${ep.port}`),
+            balancer = balancers[v?.balancer || 'round-robin'] || balancers['round-robin'],
+
+            [port, {
+              protocol: v?.protocol === 'UDP' ? 'udp' : 'tcp',
+              balancer: new balancer(targets || []),
+            }]
+          ))()
+        )
+      )
+    )
+  ),
+
+  gatewayTLS = (
+    ingress?.gatewayTLS?.listen && ingress?.gatewayTLS?.certificate?.cert && ingress?.gatewayTLS?.certificate?.key
+      ? {
+        listen: ingress.gatewayTLS.listen,
+        cert: new crypto.Certificate(ingress.gatewayTLS.certificate.cert),
+        key: new crypto.PrivateKey(ingress.gatewayTLS.certificate.key),
+        trusted: ingress.gatewayTLS.certificate.ca ? [new crypto.Certificate(ingress.gatewayTLS.certificate.ca)] : [],
+      }
+      : undefined
+  ),
+
   ) =>
 
+  // listens on the gateway port of each L4 ServiceExport
+  (pipeline => (
+    Object.entries(l4Proxies).forEach(
+      ([port, proxy]) => (
+        pipeline.listen(+port, { protocol: proxy.protocol }).link(`inbound-l4-${proxy.protocol}`)
+      )
+    ),
+    pipeline
+  ))(
   pipy({
     _passthroughTarget: undefined,
+    _l4Proxy: undefined,
+    _l4Target: undefined,
   })
   .export('main', {
     __route: undefined,
@@ -45,6 +95,42 @@
     'inbound-tls'
   )
 
+  // mTLS traffic from other clusters, the client must present a gateway certificate issued by the same CA
+  .listen(
+    gatewayTLS ? gatewayTLS.listen : 0
+  ).link('inbound-gateway-tls')
+
+  // traffic of imported services from derived Services and sidecars, it's proxied to the gateways of other clusters
+  .listen(
+    ingress?.egress?.listen || 0
+  ).link('inbound-egress')
+
+  .pipeline('inbound-egress')
+    .demuxHTTP().to(
+      $=>$.chain(['plugins/egress.js', 'plugins/default.js'])
+    )
+
+  .pipeline('inbound-gateway-tls')
+    .onStart(
+      () => (
+        (() => (
+          void(__isTLS = true)
+        ))()
+      )
+    )
+    .acceptTLS({
+      certificate: () => ({
+        cert: gatewayTLS.cert,
+        key: gatewayTLS.key,
+      }),
+      trusted: gatewayTLS?.trusted,
+      // every certificate of the ClusterSet is chained to the root CA, ONLY the gateways of clusters are accepted,
+      // certificates of workloads issued by the intermediate CAs of clusters are rejected
+      verify: (ok, cert) => (
+        ok && (cluster.isCA(cert) || Boolean(cluster.gatewayOf(cert)))
+      )
+    }).to('inbound-http')
+
   .pipeline('inbound-tls')
     .onStart(
       () => (
@@ -102,4 +188,49 @@
     .demuxHTTP().to(
       $=>$.chain(config.plugins)
     )
+  )
+
+  .pipeline('inbound-l4-tcp')
+    .onStart(
+      () => (
+        _l4Proxy = l4Proxies[__inbound.localPort],
+        _l4Target = _l4Proxy?.balancer?.next?.(),
+        undefined
+      )
+    )
+    .onEnd(
+      () => (
+        _l4Target && _l4Proxy.balancer.deselect?.(_l4Target)
+      )
+    )
+    .branch(
+      () => Boolean(_l4Target), (
+        $=>$.connect(() => _l4Target.id)
+      ),
+      (
+        $=>$.replaceStreamStart(new StreamEnd)
+      )
+    )
+
+  .pipeline('inbound-l4-udp')
+    .onStart(
+      () => (
+        _l4Proxy = l4Proxies[__inbound.localPort],
+        _l4Target = _l4Proxy?.balancer?.next?.(),
+        undefined
+      )
+    )
+    .onEnd(
+      () => (
+        _l4Target && _l4Proxy.balancer.deselect?.(_l4Target)
+      )
+    )
+    .branch(
+      () => Boolean(_l4Target), (
+        $=>$.connect(() => _l4Target.id, { protocol: 'udp' })
+      ),
+      (
+        $=>$.replaceStreamStart(new StreamEnd)
+      )
+    )
 )()
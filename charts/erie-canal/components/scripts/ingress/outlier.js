@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+((
+  ingress = pipy.solve('ingress.js'),
+  spec = ingress?.outlierDetection,
+
+  // failures of targets are reported to manager over mTLS, which ejects the exporting clusters of failing gateways
+  outlier = spec?.report && spec?.certificate?.cert && spec?.certificate?.key ? (
+    (url => ({
+      url,
+      interval: `${spec.interval || 10}s`,
+      certificate: {
+        cert: new crypto.Certificate(spec.certificate.cert),
+        key: new crypto.PrivateKey(spec.certificate.key),
+      },
+      trusted: spec.certificate.ca ? [new crypto.Certificate(spec.certificate.ca)] : [],
+      failures: {},
+    }))(new URL(spec.report))
+  ) : undefined,
+
+) => outlier && ({
+  ...outlier,
+
+  // failures are counted per ServiceImport and target, a success resets the count
+  record: (service, target, failed) => (
+    service && ((key, f) => (
+      key = `${service}|${target}`,
+      f = outlier.failures[key],
+      failed ? (
+        f = f || (outlier.failures[key] = { target, service, consecutiveFailures: 0, reported: false }),
+        f.consecutiveFailures++,
+        f.reported = false
+      ) : (
+        f && delete outlier.failures[key]
+      )
+    ))()
+  ),
+
+  // only targets which fail since last report are reported
+  take: () => (
+    Object.values(outlier.failures).filter(f => !f.reported).map(
+      f => (
+        f.reported = true,
+        { target: f.target, service: f.service, consecutiveFailures: f.consecutiveFailures }
+      )
+    )
+  ),
+}))()
@@ -59,6 +59,8 @@
       )
     ),
 
+    outlier = pipy.solve('outlier.js'),
+
   ) => pipy({
     _target: undefined,
     _service: null,
@@ -73,6 +75,7 @@
     _targetCache: null,
 
     _sourceIP: null,
+    _reports: null,
 
     _g: {
       connectionID: 0,
@@ -164,4 +167,50 @@
         $=>$.chain()
       )
     )
+    .handleMessageStart(
+      (msg) => (
+        outlier && _target && (
+          outlier.record(__route, _target.id, msg?.head?.status >= 500)
+        )
+      )
+    )
+    .handleStreamEnd(
+      (e) => (
+        outlier && _target && e.error && (
+          outlier.record(__route, _target.id, true)
+        )
+      )
+    )
+
+  .task(outlier ? outlier.interval : '10s')
+    .onStart(
+      () => (
+        _reports = outlier ? outlier.take() : [],
+        _reports.length > 0 ? (
+          new Message(
+            {
+              method: 'POST',
+              path: outlier.url.path,
+              headers: {
+                'Host': outlier.url.host,
+                'Content-Type': 'application/json',
+              }
+            },
+            JSON.encode({ reports: _reports })
+          )
+        ) : new StreamEnd
+      )
+    )
+    .muxHTTP(() => '').to(
+      $=>$.connectTLS({
+        certificate: () => outlier.certificate,
+        trusted: outlier.trusted,
+        sni: () => outlier.url.hostname,
+      }).to(
+        $=>$.connect(() => outlier.url.host)
+      )
+    )
+    .replaceMessage(
+      () => new StreamEnd
+    )
 )()
\ No newline at end of file
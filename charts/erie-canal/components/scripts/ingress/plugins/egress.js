@@ -0,0 +1,123 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+((
+    ingress = pipy.solve('ingress.js'),
+    cluster = pipy.solve('cluster.js'),
+    outlier = pipy.solve('outlier.js'),
+    egress = ingress?.egress,
+
+    certificate = egress?.certificate?.cert && egress?.certificate?.key ? {
+      cert: new crypto.Certificate(egress.certificate.cert),
+      key: new crypto.PrivateKey(egress.certificate.key),
+    } : undefined,
+
+    trusted = (
+      Object.values(
+        Object.fromEntries(
+          [egress?.certificate?.ca, ...Object.values(egress?.services || {}).flat().map(t => t?.tls?.ca)]
+            .filter(Boolean)
+            .map(ca => ['' + algo.hash(ca), new crypto.Certificate(ca)])
+        )
+      )
+    ),
+
+    services = (
+      Object.fromEntries(
+        Object.entries(egress?.services || {}).map(
+          ([k, targets]) => [k, {
+            targets: Object.fromEntries(targets.map(t => [t.address, t])),
+            // targets are weighted by GlobalTrafficPolicy of ActiveActive, otherwise they're used evenly
+            balancer: new algo.RoundRobinLoadBalancer(
+              targets.some(t => t.weight)
+                ? Object.fromEntries(targets.map(t => [t.address, t.weight || 0]))
+                : targets.map(t => t.address)
+            ),
+          }]
+        )
+      )
+    ),
+
+    // requests are sent with the exported path, unless it's already there
+    withPath = (prefix, path) => (
+      path === prefix || path.startsWith(prefix.endsWith('/') ? prefix : `${prefix}/`)
+        ? path
+        : prefix.replace(/\/+$/, '') + (path.startsWith('/') ? path : `/${path}`)
+    ),
+
+  ) => pipy({
+    _service: null,
+    _target: undefined,
+    _spec: null,
+  })
+
+  .import({
+    __route: 'main',
+  })
+
+  .pipeline()
+    .handleMessageStart(
+      (msg) => (
+        __route = egress?.hosts?.[(msg.head.headers.host || '').toLowerCase()],
+        _service = __route && services[__route],
+        _target = _service?.balancer?.next?.(),
+        _spec = _target && _service.targets[_target.id],
+        _spec?.path && (
+          msg.head.path = withPath(_spec.path, msg.head.path || '/')
+        ),
+
+        console.log("[egress] Request Host: ", msg.head.headers.host),
+        console.log("[egress] _target.id", (_target || {id : ''}).id)
+      )
+    )
+    .branch(
+      () => Boolean(_spec) && !_spec.tls, (
+        $=>$.muxHTTP(() => _target.id).to(
+          $=>$.connect(() => _target.id)
+        )
+      ), () => Boolean(_spec), (
+        $=>$.muxHTTP(() => _target.id).to(
+          $=>$.connectTLS({
+            certificate: () => certificate,
+            trusted,
+            sni: () => _spec.tls.sni,
+            // the gateway must identify itself as the exporting cluster, not just any cluster of the ClusterSet
+            verify: (ok, cert) => (
+              ok && (cluster.isCA(cert) || cluster.namesOf(cert).includes(_spec.tls.sni))
+            )
+          }).to(
+            $=>$.connect(() => _target.id)
+          )
+        )
+      ), (
+        $=>$.chain()
+      )
+    )
+    // failures of gateways are reported with the ServiceImport, so that the exporting cluster is ejected from it
+    .handleMessageStart(
+      (msg) => (
+        outlier && _spec && (
+          outlier.record(egress.imports?.[__route], _target.id, msg?.head?.status >= 500)
+        )
+      )
+    )
+    .handleStreamEnd(
+      (e) => (
+        outlier && _spec && e.error && (
+          outlier.record(egress.imports?.[__route], _target.id, true)
+        )
+      )
+    )
+)()
@@ -15,9 +15,33 @@
  */
 
 
-(config =>
+((
+  config = JSON.decode(pipy.load('config/config.json')),
 
-pipy()
+  registry = JSON.decode(pipy.load('config/registry.json')),
+
+  // addresses of a service are weighted if it has a GlobalTrafficPolicy of ActiveActive, otherwise they're used evenly
+  balancers = (
+    Object.fromEntries(
+      Object.entries(registry?.services || {}).map(
+        ([k, addresses]) => [k, new algo.RoundRobinLoadBalancer(registry?.weights?.[k] || addresses)]
+      )
+    )
+  ),
+
+  // imported services are matched by the VIP:port which requests are sent to, or by the Host header
+  serviceOf = (host) => (
+    registry?.vips?.[`${__inbound.destinationAddress}:${__inbound.destinationPort}`] ||
+    registry?.vips?.[host] ||
+    registry?.vips?.[`${host}:80`]
+  ),
+
+) =>
+
+pipy({
+  _service: undefined,
+  _target: undefined,
+})
 
 .export('proxy', {
   __turnDown: false
@@ -26,10 +50,42 @@ pipy()
 .listen(+os.env['PIPY_PROXY_PORT'] || config.listen)
   .demuxHTTP('forward')
 
+// outbound traffic of the Pod which is redirected to the sidecar, it's disabled if the port is 0
+.listen(+os.env['PIPY_OUTBOUND_PORT'] || config.outbound || 0, { transparent: true })
+  .demuxHTTP('outbound')
+
 .pipeline('forward')
   .muxHTTP('connection', ()=>'')
 
 .pipeline('connection')
   .connect(os.env['USER_PORT'] ? `localhost:${os.env['USER_PORT']}` : `localhost:${config.userPort}`)
 
-)(JSON.decode(pipy.load('config/config.json')))
\ No newline at end of file
+.pipeline('outbound')
+  .handleMessageStart(
+    (msg) => (
+      _service = serviceOf((msg.head.headers.host || '').toLowerCase()),
+      _target = _service && balancers[_service]?.next?.(),
+      // Pods of headless ServiceImport are reached through the gateway of exporting cluster by their own hosts
+      _target && registry?.hosts?.[_service] && (
+        msg.head.headers.host = registry.hosts[_service]
+      )
+    )
+  )
+  .branch(
+    () => Boolean(_target), (
+      $=>$.muxHTTP(() => _target.id).to(
+        $=>$.connect(() => _target.id)
+      )
+    ), (
+      $=>$.replaceMessage(
+        new Message({
+          "status": 404,
+          "headers": {
+            "Server": "pipy/0.90.0"
+          }
+        }, 'Service Not Found')
+      )
+    )
+  )
+
+)()
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/dns"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"k8s.io/klog/v2"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"time"
+)
+
+func setupDNS(mgr manager.Manager, api *kube.K8sAPI, mc *config.MeshConfig) {
+	if !mc.ClusterSet.DNS.Enabled {
+		return
+	}
+
+	// FIXME: make it configurable
+	resyncPeriod := 15 * time.Minute
+
+	server := dns.NewServer(fmt.Sprintf(":%d", mc.ClusterSet.DNS.Port), api, resyncPeriod)
+	if err := mgr.Add(server); err != nil {
+		klog.Error(err, "unable add DNS server to the manager")
+		os.Exit(1)
+	}
+}
@@ -49,6 +49,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	gwschema "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -61,6 +62,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(flomeshscheme.AddToScheme(scheme))
 	utilruntime.Must(gwschema.AddToScheme(scheme))
+	utilruntime.Must(mcsv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -127,6 +129,12 @@ func main() {
 
 	registerEventHandler(mgr, k8sApi, controlPlaneConfigStore, certMgr)
 
+	// setup DNS server of clusterset.local
+	setupDNS(mgr, k8sApi, mc)
+
+	// setup outlier detection of imported services
+	setupOutlierDetection(mgr, k8sApi, controlPlaneConfigStore, certMgr)
+
 	// add endpoints for Liveness and Readiness check
 	addLivenessAndReadinessCheck(mgr)
 	//+kubebuilder:scaffold:builder
@@ -165,6 +173,10 @@ func loadManagerOptions(configFile string) ctrl.Options {
 		}
 	}
 
+	// connectors are stopped before releasing the lease, so that the standby replica takes over
+	// without waiting for the lease to expire
+	options.LeaderElectionReleaseOnCancel = true
+
 	return options
 }
 
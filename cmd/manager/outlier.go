@@ -0,0 +1,45 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/flomesh-io/ErieCanal/pkg/certificate"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"github.com/flomesh-io/ErieCanal/pkg/outlier"
+	"k8s.io/klog/v2"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"time"
+)
+
+func setupOutlierDetection(mgr manager.Manager, api *kube.K8sAPI, configStore *config.Store, certMgr certificate.Manager) {
+	mc := configStore.MeshConfig.GetConfig()
+	if !mc.ClusterSet.OutlierDetection.Enabled {
+		return
+	}
+
+	// FIXME: make it configurable
+	resyncPeriod := 15 * time.Minute
+
+	server := outlier.NewServer(fmt.Sprintf(":%d", mc.ClusterSet.OutlierDetection.Port), api, configStore, certMgr, resyncPeriod)
+	if err := mgr.Add(server); err != nil {
+		klog.Error(err, "unable add outlier detection server to the manager")
+		os.Exit(1)
+	}
+}
@@ -19,6 +19,9 @@ package main
 import (
 	clusterv1alpha1 "github.com/flomesh-io/ErieCanal/controllers/cluster/v1alpha1"
 	gatewayv1beta1 "github.com/flomesh-io/ErieCanal/controllers/gateway/v1beta1"
+	gtpv1alpha1 "github.com/flomesh-io/ErieCanal/controllers/globaltrafficpolicy/v1alpha1"
+	mcsv1alpha1 "github.com/flomesh-io/ErieCanal/controllers/mcs/v1alpha1"
+	mcev1alpha1 "github.com/flomesh-io/ErieCanal/controllers/multiclusterendpoint/v1alpha1"
 	nsigv1alpha1 "github.com/flomesh-io/ErieCanal/controllers/namespacedingress/v1alpha1"
 	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/controllers/serviceexport/v1alpha1"
 	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/controllers/serviceimport/v1alpha1"
@@ -27,16 +30,18 @@ import (
 	"github.com/flomesh-io/ErieCanal/pkg/config"
 	"github.com/flomesh-io/ErieCanal/pkg/event"
 	"github.com/flomesh-io/ErieCanal/pkg/kube"
-	"github.com/flomesh-io/ErieCanal/pkg/util"
 	"k8s.io/klog/v2"
 	"os"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	mcsapi "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 )
 
 func registerReconcilers(mgr manager.Manager, api *kube.K8sAPI, controlPlaneConfigStore *config.Store, certMgr certificate.Manager, broker *event.Broker) {
 	registerCluster(mgr, api, controlPlaneConfigStore, broker, certMgr)
 	registerServiceExport(mgr, api, controlPlaneConfigStore, broker)
 	registerServiceImport(mgr, api, controlPlaneConfigStore)
+	registerGlobalTrafficPolicy(mgr, api, controlPlaneConfigStore)
+	registerMultiClusterEndpoint(mgr, api, controlPlaneConfigStore)
 
 	mc := controlPlaneConfigStore.MeshConfig.GetConfig()
 	if mc.GatewayApi.Enabled {
@@ -50,6 +55,10 @@ func registerReconcilers(mgr manager.Manager, api *kube.K8sAPI, controlPlaneConf
 	if mc.ServiceLB.Enabled {
 		registerServiceLB(mgr, api, controlPlaneConfigStore)
 	}
+
+	if mc.ClusterSet.MCSAPI.Enabled {
+		registerMCSAPI(mgr, api, controlPlaneConfigStore)
+	}
 }
 
 func registerCluster(mgr manager.Manager, api *kube.K8sAPI, controlPlaneConfigStore *config.Store, broker *event.Broker, certMgr certificate.Manager) {
@@ -61,7 +70,6 @@ func registerCluster(mgr manager.Manager, api *kube.K8sAPI, controlPlaneConfigSt
 		controlPlaneConfigStore,
 		broker,
 		certMgr,
-		util.RegisterOSExitHandlers(),
 	)).SetupWithManager(mgr); err != nil {
 		klog.Fatal(err, "unable to create controller", "controller", "Cluster")
 		os.Exit(1)
@@ -95,6 +103,32 @@ func registerServiceImport(mgr manager.Manager, api *kube.K8sAPI, controlPlaneCo
 	}
 }
 
+func registerGlobalTrafficPolicy(mgr manager.Manager, api *kube.K8sAPI, controlPlaneConfigStore *config.Store) {
+	if err := (&gtpv1alpha1.GlobalTrafficPolicyReconciler{
+		Client:                  mgr.GetClient(),
+		K8sAPI:                  api,
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("GlobalTrafficPolicy"),
+		ControlPlaneConfigStore: controlPlaneConfigStore,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Fatal(err, "unable to create controller", "controller", "GlobalTrafficPolicy")
+		os.Exit(1)
+	}
+}
+
+func registerMultiClusterEndpoint(mgr manager.Manager, api *kube.K8sAPI, controlPlaneConfigStore *config.Store) {
+	if err := (&mcev1alpha1.MultiClusterEndpointReconciler{
+		Client:                  mgr.GetClient(),
+		K8sAPI:                  api,
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("MultiClusterEndpoint"),
+		ControlPlaneConfigStore: controlPlaneConfigStore,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Fatal(err, "unable to create controller", "controller", "MultiClusterEndpoint")
+		os.Exit(1)
+	}
+}
+
 func registerNamespacedIngress(mgr manager.Manager, api *kube.K8sAPI, controlPlaneConfigStore *config.Store, certMgr certificate.Manager) {
 	if err := (&nsigv1alpha1.NamespacedIngressReconciler{
 		Client:                  mgr.GetClient(),
@@ -163,3 +197,32 @@ func registerServiceLB(mgr manager.Manager, api *kube.K8sAPI, store *config.Stor
 		os.Exit(1)
 	}
 }
+
+func registerMCSAPI(mgr manager.Manager, api *kube.K8sAPI, store *config.Store) {
+	// the CRDs of upstream MCS API are not shipped with ErieCanal
+	if _, err := api.DiscoveryClient.ServerResourcesForGroupVersion(mcsapi.GroupVersion.String()); err != nil {
+		klog.Warningf("%s is not available, skip mirroring upstream MCS API: %s", mcsapi.GroupVersion, err)
+		return
+	}
+
+	if err := (&mcsv1alpha1.ServiceExportReconciler{
+		Client:                  mgr.GetClient(),
+		K8sAPI:                  api,
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("MCSServiceExport"),
+		ControlPlaneConfigStore: store,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Fatal(err, "unable to create controller", "controller", "MCS(ServiceExport)")
+		os.Exit(1)
+	}
+	if err := (&mcsv1alpha1.ServiceImportReconciler{
+		Client:                  mgr.GetClient(),
+		K8sAPI:                  api,
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("MCSServiceImport"),
+		ControlPlaneConfigStore: store,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Fatal(err, "unable to create controller", "controller", "MCS(ServiceImport)")
+		os.Exit(1)
+	}
+}
@@ -161,7 +161,7 @@ func registerToWebhookServer(mgr manager.Manager, api *kube.K8sAPI, controlPlane
 		webhooks.DefaultingWebhookFor(clusterwh.NewDefaulter(api, controlPlaneConfigStore)),
 	)
 	hookServer.Register(commons.ClusterValidatingWebhookPath,
-		webhooks.ValidatingWebhookFor(clusterwh.NewValidator(api)),
+		webhooks.ValidatingWebhookFor(clusterwh.NewValidator(api, controlPlaneConfigStore)),
 	)
 
 	// NamespacedIngress
@@ -177,7 +177,7 @@ func registerToWebhookServer(mgr manager.Manager, api *kube.K8sAPI, controlPlane
 		webhooks.DefaultingWebhookFor(svcexpwh.NewDefaulter(api, controlPlaneConfigStore)),
 	)
 	hookServer.Register(commons.ServiceExportValidatingWebhookPath,
-		webhooks.ValidatingWebhookFor(svcexpwh.NewValidator(api)),
+		webhooks.ValidatingWebhookFor(svcexpwh.NewValidator(api, controlPlaneConfigStore)),
 	)
 
 	// ServiceImport
@@ -41,13 +41,23 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sync"
 	"time"
 )
 
+// finalizerName is added to remote Clusters, the cluster is cleaned up when it leaves the ClusterSet
+const finalizerName = "multicluster.flomesh.io/cluster-cleanup"
+
 // ClusterReconciler reconciles a Cluster object
 type ClusterReconciler struct {
 	client.Client
@@ -58,7 +68,9 @@ type ClusterReconciler struct {
 	broker      *event.Broker
 	certMgr     certificate.Manager
 	backgrounds map[string]*connectorBackground
-	mu          sync.Mutex
+	// exports of all clusters, indexed by namespace/name of service then cluster key
+	exports map[string]map[string]*event.ServiceExportEvent
+	mu      sync.Mutex
 }
 
 type connectorBackground struct {
@@ -75,9 +87,8 @@ func New(
 	store *config.Store,
 	broker *event.Broker,
 	certMgr certificate.Manager,
-	stop <-chan struct{},
 ) *ClusterReconciler {
-	r := &ClusterReconciler{
+	return &ClusterReconciler{
 		Client:      client,
 		Scheme:      scheme,
 		k8sAPI:      api,
@@ -86,11 +97,38 @@ func New(
 		broker:      broker,
 		certMgr:     certMgr,
 		backgrounds: make(map[string]*connectorBackground),
+		exports:     make(map[string]map[string]*event.ServiceExportEvent),
 	}
+}
 
-	go r.processEvent(broker, stop)
+// Start implements manager.Runnable, it runs only in the elected leader as connectors are created
+// by reconciling Clusters. Once the leadership is lost or the manager is stopping, all connectors are
+// stopped before the lease is released, the connectors of new leader rebuild the states from scratch.
+func (r *ClusterReconciler) Start(ctx context.Context) error {
+	klog.Infof("Start processing events of connectors ...")
+	r.processEvent(r.broker, ctx.Done())
 
-	return r
+	r.stopConnectors()
+
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (r *ClusterReconciler) NeedLeaderElection() bool {
+	return true
+}
+
+// stopConnectors stops all running connectors and forgets the exports collected by them
+func (r *ClusterReconciler) stopConnectors() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, bg := range r.backgrounds {
+		klog.Infof("Stopping connector of cluster %s ...", key)
+		close(bg.context.StopCh)
+		delete(r.backgrounds, key)
+	}
+	r.exports = make(map[string]map[string]*event.ServiceExportEvent)
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -124,6 +162,19 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	if !cluster.Spec.IsInCluster {
+		if cluster.DeletionTimestamp != nil {
+			return r.leaveClusterSet(ctx, cluster)
+		}
+
+		if !controllerutil.ContainsFinalizer(cluster, finalizerName) {
+			controllerutil.AddFinalizer(cluster, finalizerName)
+			if err := r.Update(ctx, cluster); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
 	mc := r.configStore.MeshConfig.GetConfig()
 
 	result, err := r.deriveCodebases(mc)
@@ -131,22 +182,76 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return result, err
 	}
 
+	kubeconfig, err := r.kubeconfigData(ctx, cluster)
+	if err != nil {
+		klog.Errorf("Failed to get kubeconfig for cluster %q: %s", cluster.Key(), err)
+		// the Secret is watched, it's reconciled again once the Secret is fixed
+		return r.failedJoinClusterSet(ctx, cluster, err.Error())
+	}
+
+	klog.V(5).Infof("Cluster key is %s", cluster.Key())
+	if result, err = r.ensureConnector(ctx, cluster, mc, kubeconfig); err != nil {
+		return result, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// leaveClusterSet removes the endpoints of the cluster from the ServiceImports of all other clusters,
+// resets the MeshConfig of the cluster, then stops the connector and removes the finalizer
+func (r *ClusterReconciler) leaveClusterSet(ctx context.Context, cluster *clusterv1alpha1.Cluster) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(cluster, finalizerName) {
+		r.destroyConnector(cluster)
+		return ctrl.Result{}, nil
+	}
+
 	key := cluster.Key()
-	klog.V(5).Infof("Cluster key is %s", key)
-	bg, exists := r.backgrounds[key]
-	if exists && bg.context.SpecHash != util.SimpleHash(cluster.Spec) {
-		klog.V(5).Infof("Background context of cluster [%s] exists, ")
-		// exists and the spec changed, then stop it and start a new one
-		if result, err = r.recreateConnector(ctx, bg, cluster, mc); err != nil {
-			return result, err
+	klog.Infof("Cluster %s is leaving ClusterSet, cleaning up ...", key)
+	r.forgetExportsOfCluster(key)
+
+	r.mu.Lock()
+	connectors := make(map[string]*conn.RemoteConnector)
+	for k, bg := range r.backgrounds {
+		if !bg.isInCluster {
+			connectors[k] = bg.connector.(*conn.RemoteConnector)
 		}
-	} else if !exists {
-		// doesn't exist, just create a new one
-		if result, err = r.createConnector(ctx, cluster, mc); err != nil {
-			return result, err
+	}
+	r.mu.Unlock()
+
+	for k, connector := range connectors {
+		if k == key {
+			continue
+		}
+
+		if err := connector.RemoveEndpointsOfCluster(key); err != nil {
+			klog.Errorf("Failed to remove endpoints of cluster %s from cluster %s: %s", key, k, err)
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "CleanupFailed", "Failed to remove endpoints from cluster %s: %s", k, err)
+			return ctrl.Result{}, err
 		}
+
+		if err := connector.ForgetImportsOfCluster(key); err != nil {
+			klog.Errorf("Failed to remove import status of cluster %s from cluster %s: %s", key, k, err)
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "CleanupFailed", "Failed to remove import status from cluster %s: %s", k, err)
+			return ctrl.Result{}, err
+		}
+	}
+	r.recorder.Eventf(cluster, corev1.EventTypeNormal, "EndpointsRemoved", "Endpoints of cluster %s are removed from ServiceImports of other clusters", key)
+
+	// the cluster might be unreachable, it doesn't block leaving ClusterSet
+	if connector, ok := connectors[key]; !ok {
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "MeshConfigNotReset", "Cluster %s is not connected, MeshConfig can't be reset", key)
+	} else if err := connector.LeaveClusterSet(); err != nil {
+		klog.Errorf("Failed to reset MeshConfig of cluster %s: %s", key, err)
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "MeshConfigNotReset", "Failed to reset MeshConfig of cluster %s: %s", key, err)
 	} else {
-		klog.V(2).Infof("The connector %s already exists and the spec doesn't change", key)
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "MeshConfigReset", "MeshConfig of cluster %s is reset", key)
+	}
+
+	r.destroyConnector(cluster)
+
+	controllerutil.RemoveFinalizer(cluster, finalizerName)
+	if err := r.Update(ctx, cluster); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
@@ -168,21 +273,29 @@ func (r *ClusterReconciler) deriveCodebases(mc *config.MeshConfig) (ctrl.Result,
 	return ctrl.Result{}, nil
 }
 
-func (r *ClusterReconciler) createConnector(ctx context.Context, cluster *clusterv1alpha1.Cluster, mc *config.MeshConfig) (ctrl.Result, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	return r.newConnector(ctx, cluster, mc)
-}
-
-func (r *ClusterReconciler) recreateConnector(ctx context.Context, bg *connectorBackground, cluster *clusterv1alpha1.Cluster, mc *config.MeshConfig) (ctrl.Result, error) {
+// ensureConnector starts the connector of the cluster if it's not running, or restarts it if the spec or
+// kubeconfig changed. The lookup and replacement are done under the lock, so that concurrent reconciles
+// never run two connectors of the same cluster
+func (r *ClusterReconciler) ensureConnector(ctx context.Context, cluster *clusterv1alpha1.Cluster, mc *config.MeshConfig, kubeconfig []byte) (ctrl.Result, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	close(bg.context.StopCh)
-	delete(r.backgrounds, cluster.Key())
-
-	return r.newConnector(ctx, cluster, mc)
+	key := cluster.Key()
+	bg, exists := r.backgrounds[key]
+	switch {
+	case !exists:
+		// doesn't exist, just create a new one
+		return r.newConnector(ctx, cluster, mc, kubeconfig)
+	case bg.context.SpecHash != connectorHash(cluster, kubeconfig):
+		// exists and the spec or kubeconfig changed, then stop it and start a new one
+		klog.V(5).Infof("Background context of cluster [%s] changed, recreating the connector ...", key)
+		close(bg.context.StopCh)
+		delete(r.backgrounds, key)
+		return r.newConnector(ctx, cluster, mc, kubeconfig)
+	default:
+		klog.V(2).Infof("The connector %s already exists and the spec doesn't change", key)
+		return ctrl.Result{}, nil
+	}
 }
 
 func (r *ClusterReconciler) destroyConnector(cluster *clusterv1alpha1.Cluster) {
@@ -196,10 +309,11 @@ func (r *ClusterReconciler) destroyConnector(cluster *clusterv1alpha1.Cluster) {
 	}
 }
 
-func (r *ClusterReconciler) newConnector(ctx context.Context, cluster *clusterv1alpha1.Cluster, mc *config.MeshConfig) (ctrl.Result, error) {
+// newConnector creates and runs the connector of the cluster, the caller must hold the lock
+func (r *ClusterReconciler) newConnector(ctx context.Context, cluster *clusterv1alpha1.Cluster, mc *config.MeshConfig, kubeconfigData []byte) (ctrl.Result, error) {
 	key := cluster.Key()
 
-	kubeconfig, result, err := getKubeConfig(cluster)
+	kubeconfig, result, err := getKubeConfig(cluster, kubeconfigData)
 	if err != nil {
 		klog.Errorf("Failed to get kubeconfig for cluster %q: %s", cluster.Key(), err)
 		return result, err
@@ -211,10 +325,14 @@ func (r *ClusterReconciler) newConnector(ctx context.Context, cluster *clusterv1
 	}
 
 	background := cctx.ConnectorContext{
-		ClusterKey:      key,
-		KubeConfig:      kubeconfig,
-		ConnectorConfig: connCfg,
-		SpecHash:        util.SimpleHash(cluster.Spec),
+		ClusterKey:       key,
+		KubeConfig:       kubeconfig,
+		ConnectorConfig:  connCfg,
+		ControlPlane:     mc.ClusterSet.ControlPlane,
+		GatewayResolver:  mc.ClusterSet.GatewayResolver,
+		GatewayTLS:       mc.ClusterSet.GatewayTLS,
+		SpecHash:         connectorHash(cluster, kubeconfigData),
+		ClusterSetConfig: r.configStore.MeshConfig,
 	}
 	_, cancel := context.WithCancel(&background)
 	stop := util.RegisterExitHandlers(cancel)
@@ -227,36 +345,61 @@ func (r *ClusterReconciler) newConnector(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, err
 	}
 
-	r.backgrounds[key] = &connectorBackground{
+	current := &connectorBackground{
 		isInCluster: cluster.Spec.IsInCluster,
 		context:     background,
 		connector:   connector,
 	}
+	r.backgrounds[key] = current
 
-	success := true
-	errorMsg := ""
+	clusterObjKey := client.ObjectKeyFromObject(cluster)
+	isInCluster := cluster.Spec.IsInCluster
 	go func() {
-		if err := connector.Run(stop); err != nil {
-			success = false
-			errorMsg = err.Error()
-			klog.Errorf("Failed to run connector for cluster %q: %s", cluster.Key(), err)
+		err := connector.Run(stop)
+		if err == nil {
+			return
+		}
+		klog.Errorf("Failed to run connector for cluster %q: %s", key, err)
+
+		// it's already stopped if it has been replaced by a new connector of the cluster
+		r.mu.Lock()
+		replaced := r.backgrounds[key] != current
+		if !replaced {
 			close(stop)
 			delete(r.backgrounds, key)
 		}
+		r.mu.Unlock()
+
+		// the status is owned by the new connector once it's replaced
+		if !replaced && !isInCluster {
+			r.reportJoinFailure(clusterObjKey, err)
+		}
 	}()
 
 	if !cluster.Spec.IsInCluster {
-		if success {
-			return r.successJoinClusterSet(ctx, cluster, mc)
-		} else {
-			return r.failedJoinClusterSet(ctx, cluster, errorMsg)
-		}
+		return r.successJoinClusterSet(ctx, cluster, mc)
 	}
 
 	return ctrl.Result{}, nil
 }
 
-func getKubeConfig(cluster *clusterv1alpha1.Cluster) (*rest.Config, ctrl.Result, error) {
+// reportJoinFailure marks the cluster as failed to join ClusterSet once its connector fails, it runs
+// in the background so the latest Cluster is fetched instead of sharing the one being reconciled
+func (r *ClusterReconciler) reportJoinFailure(key client.ObjectKey, joinErr error) {
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &clusterv1alpha1.Cluster{}
+		if err := r.Get(context.TODO(), key, cluster); err != nil {
+			return err
+		}
+
+		_, err := r.failedJoinClusterSet(context.TODO(), cluster, joinErr.Error())
+		return err
+	}); err != nil {
+		klog.Errorf("Failed to update status of cluster %s: %s", key, err)
+	}
+}
+
+func getKubeConfig(cluster *clusterv1alpha1.Cluster, data []byte) (*rest.Config, ctrl.Result, error) {
 	if cluster.Spec.IsInCluster {
 		kubeconfig, err := rest.InClusterConfig()
 		if err != nil {
@@ -265,14 +408,14 @@ func getKubeConfig(cluster *clusterv1alpha1.Cluster) (*rest.Config, ctrl.Result,
 
 		return kubeconfig, ctrl.Result{}, nil
 	} else {
-		return remoteKubeConfig(cluster)
+		return remoteKubeConfig(data)
 	}
 }
 
-func remoteKubeConfig(cluster *clusterv1alpha1.Cluster) (*rest.Config, ctrl.Result, error) {
+func remoteKubeConfig(data []byte) (*rest.Config, ctrl.Result, error) {
 	// use the current context in kubeconfig
 	kubeconfig, err := clientcmd.BuildConfigFromKubeconfigGetter("", func() (*clientcmdapi.Config, error) {
-		cfg, err := clientcmd.Load([]byte(cluster.Spec.Kubeconfig))
+		cfg, err := clientcmd.Load(data)
 		if err != nil {
 			return nil, err
 		}
@@ -287,6 +430,63 @@ func remoteKubeConfig(cluster *clusterv1alpha1.Cluster) (*rest.Config, ctrl.Resu
 	return kubeconfig, ctrl.Result{}, nil
 }
 
+// kubeconfigData returns the raw kubeconfig of a remote cluster, it's read from the referenced Secret
+// if spec.kubeconfigSecretRef is set, otherwise from spec.kubeconfig
+func (r *ClusterReconciler) kubeconfigData(ctx context.Context, cluster *clusterv1alpha1.Cluster) ([]byte, error) {
+	if cluster.Spec.IsInCluster {
+		return nil, nil
+	}
+
+	ref := cluster.Spec.KubeconfigSecretRef
+	if ref == nil {
+		return []byte(cluster.Spec.Kubeconfig), nil
+	}
+
+	secretKey, err := kubeconfigSecretKey(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, secretKey, secret); err != nil {
+		return nil, err
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = commons.DefaultKubeconfigSecretKey
+	}
+
+	data, ok := secret.Data[key]
+	if !ok || len(data) == 0 {
+		return nil, fmt.Errorf("key %q is not found in Secret %s/%s", key, secret.Namespace, secret.Name)
+	}
+
+	return data, nil
+}
+
+// kubeconfigSecretKey returns the key of the referenced Secret, it must be in the namespace of ErieCanal
+func kubeconfigSecretKey(ref *clusterv1alpha1.SecretKeyReference) (client.ObjectKey, error) {
+	namespace := config.GetErieCanalNamespace()
+	if ref.Namespace != "" && ref.Namespace != namespace {
+		return client.ObjectKey{}, fmt.Errorf("kubeconfig Secret must be in namespace %s, got %s", namespace, ref.Namespace)
+	}
+
+	return client.ObjectKey{Namespace: namespace, Name: ref.Name}, nil
+}
+
+// connectorHash changes if either the spec or the kubeconfig of the cluster changes,
+// so that rotating the credentials in Secret restarts the connector
+func connectorHash(cluster *clusterv1alpha1.Cluster, kubeconfig []byte) string {
+	return util.SimpleHash(struct {
+		Spec       clusterv1alpha1.ClusterSpec
+		Kubeconfig string
+	}{
+		Spec:       cluster.Spec,
+		Kubeconfig: string(kubeconfig),
+	})
+}
+
 func (r *ClusterReconciler) connectorConfig(cluster *clusterv1alpha1.Cluster, mc *config.MeshConfig) (*config.ConnectorConfig, error) {
 	if cluster.Spec.IsInCluster {
 		return config.NewConnectorConfig(
@@ -317,6 +517,10 @@ func (r *ClusterReconciler) processEvent(broker *event.Broker, stop <-chan struc
 	msgBus := broker.GetMessageBus()
 	svcExportCreatedCh := msgBus.Sub(string(event.ServiceExportCreated))
 	defer broker.Unsub(msgBus, svcExportCreatedCh)
+	svcExportDeletedCh := msgBus.Sub(string(event.ServiceExportDeleted))
+	defer broker.Unsub(msgBus, svcExportDeletedCh)
+	clusterProbedCh := msgBus.Sub(string(event.ClusterProbed))
+	defer broker.Unsub(msgBus, clusterProbedCh)
 
 	for {
 		// FIXME: refine it later
@@ -348,82 +552,67 @@ func (r *ClusterReconciler) processEvent(broker *event.Broker, stop <-chan struc
 				continue
 			}
 
-			// check ServiceExport Status, Invalid and Conflict ServiceExport is ignored
+			// check ServiceExport Status, Invalid ServiceExport is ignored,
+			// Conflict is determined by comparing with the exports of other clusters
 			export := svcExportEvt.ServiceExport
 			if metautil.IsStatusConditionFalse(export.Status.Conditions, string(svcexpv1alpha1.ServiceExportValid)) {
 				klog.Warningf("ServiceExport %#v is ignored due to Valid status is false", export)
 				continue
 			}
-			if metautil.IsStatusConditionTrue(export.Status.Conditions, string(svcexpv1alpha1.ServiceExportConflict)) {
-				klog.Warningf("ServiceExport %#v is ignored due to Conflict status is true", export)
-				continue
-			}
 
 			r.processServiceExportCreatedEvent(svcExportEvt)
-		case <-stop:
-			klog.Infof("Received stop signal.")
-			return
-		}
-	}
-}
+		case msg, ok := <-svcExportDeletedCh:
+			mc := r.configStore.MeshConfig.GetConfig()
+			if mc.IsManaged && mc.Cluster.ControlPlaneUID != "" && mc.Cluster.UID != mc.Cluster.ControlPlaneUID {
+				continue
+			}
 
-func (r *ClusterReconciler) processServiceExportCreatedEvent(svcExportEvt *event.ServiceExportEvent) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+			if !ok {
+				klog.Warningf("Channel closed for ServiceExport")
+				continue
+			}
+			klog.V(5).Infof("Received event ServiceExportDeleted %v", msg)
 
-	export := svcExportEvt.ServiceExport
-	if r.isFirstTimeExport(svcExportEvt) {
-		klog.V(5).Infof("[%s] ServiceExport %s/%s is exported first in the cluster set, will be accepted", svcExportEvt.Geo.Key(), export.Namespace, export.Name)
-		r.acceptServiceExport(svcExportEvt)
-	} else {
-		valid, err := r.isValidServiceExport(svcExportEvt)
-		if valid {
-			klog.V(5).Infof("[%s] ServiceExport %s/%s is valid, will be accepted", svcExportEvt.Geo.Key(), export.Namespace, export.Name)
-			r.acceptServiceExport(svcExportEvt)
-		} else {
-			klog.V(5).Infof("[%s] ServiceExport %s/%s is invalid, will be rejected", svcExportEvt.Geo.Key(), export.Namespace, export.Name)
-			r.rejectServiceExport(svcExportEvt, err)
-		}
-	}
-}
+			e, ok := msg.(event.Message)
+			if !ok {
+				klog.Errorf("Received unexpected message %T on channel, expected Message", e)
+				continue
+			}
 
-func (r *ClusterReconciler) isFirstTimeExport(event *event.ServiceExportEvent) bool {
-	export := event.ServiceExport
-	for _, bg := range r.backgrounds {
-		if bg.isInCluster {
-			continue
-		}
-		remoteConnector := bg.connector.(*conn.RemoteConnector)
-		if remoteConnector.ServiceImportExists(export) {
-			klog.Warningf("[%s] ServiceExport %s/%s exists in Cluster %s", event.Geo.Key(), export.Namespace, export.Name, bg.context.ClusterKey)
-			return false
-		}
-	}
+			svcExportEvt, ok := e.OldObj.(*event.ServiceExportEvent)
+			if !ok {
+				klog.Errorf("Received unexpected object %T, expected *event.ServiceExportEvent", svcExportEvt)
+				continue
+			}
 
-	return true
-}
+			r.processServiceExportDeletedEvent(svcExportEvt)
+		case msg, ok := <-clusterProbedCh:
+			if !ok {
+				klog.Warningf("Channel closed for Cluster")
+				continue
+			}
+			klog.V(5).Infof("Received event ClusterProbed %v", msg)
 
-func (r *ClusterReconciler) isValidServiceExport(svcExportEvt *event.ServiceExportEvent) (bool, error) {
-	export := svcExportEvt.ServiceExport
-	for _, bg := range r.backgrounds {
-		if bg.isInCluster {
-			continue
-		}
+			e, ok := msg.(event.Message)
+			if !ok {
+				klog.Errorf("Received unexpected message %T on channel, expected Message", e)
+				continue
+			}
 
-		connectorContext := bg.context
-		if connectorContext.ClusterKey == svcExportEvt.ClusterKey() {
-			// no need to test against itself
-			continue
-		}
+			probeEvt, ok := e.NewObj.(*event.ClusterProbeEvent)
+			if !ok {
+				klog.Errorf("Received unexpected object %T, expected *event.ClusterProbeEvent", probeEvt)
+				continue
+			}
 
-		remoteConnector := bg.connector.(*conn.RemoteConnector)
-		if err := remoteConnector.ValidateServiceExport(svcExportEvt.ServiceExport, svcExportEvt.Service); err != nil {
-			klog.Warningf("[%s] ServiceExport %s/%s has conflict in Cluster %s", svcExportEvt.Geo.Key(), export.Namespace, export.Name, connectorContext.ClusterKey)
-			return false, err
+			if err := r.updateProbeStatus(context.TODO(), probeEvt); err != nil {
+				klog.Errorf("Failed to update probe status of cluster %s: %s", probeEvt.ClusterKey(), err)
+			}
+		case <-stop:
+			klog.Infof("Received stop signal.")
+			return
 		}
 	}
-
-	return true, nil
 }
 
 func (r *ClusterReconciler) acceptServiceExport(svcExportEvt *event.ServiceExportEvent) {
@@ -448,15 +637,10 @@ func (r *ClusterReconciler) rejectServiceExport(svcExportEvt *event.ServiceExpor
 	)
 }
 
+// successJoinClusterSet marks the cluster as Managed, a standby control plane marks it as Standby
+// until the probes report it holds the Lease of the cluster
 func (r *ClusterReconciler) successJoinClusterSet(ctx context.Context, cluster *clusterv1alpha1.Cluster, mc *config.MeshConfig) (ctrl.Result, error) {
-	metautil.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
-		Type:               string(clusterv1alpha1.ClusterManaged),
-		Status:             metav1.ConditionTrue,
-		ObservedGeneration: cluster.Generation,
-		LastTransitionTime: metav1.Time{Time: time.Now()},
-		Reason:             "Success",
-		Message:            fmt.Sprintf("Cluster %s joined ClusterSet successfully.", cluster.Key()),
-	})
+	metautil.SetStatusCondition(&cluster.Status.Conditions, managedCondition(cluster, !mc.ClusterSet.ControlPlane.Standby))
 
 	if err := r.Status().Update(ctx, cluster); err != nil {
 		return ctrl.Result{}, err
@@ -465,6 +649,28 @@ func (r *ClusterReconciler) successJoinClusterSet(ctx context.Context, cluster *
 	return ctrl.Result{}, nil
 }
 
+func managedCondition(cluster *clusterv1alpha1.Cluster, active bool) metav1.Condition {
+	if active {
+		return metav1.Condition{
+			Type:               string(clusterv1alpha1.ClusterManaged),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: cluster.Generation,
+			LastTransitionTime: metav1.Time{Time: time.Now()},
+			Reason:             "Success",
+			Message:            fmt.Sprintf("Cluster %s joined ClusterSet successfully.", cluster.Key()),
+		}
+	}
+
+	return metav1.Condition{
+		Type:               string(clusterv1alpha1.ClusterManaged),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: cluster.Generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             "Standby",
+		Message:            fmt.Sprintf("Cluster %s is managed by another control plane, standing by.", cluster.Key()),
+	}
+}
+
 func (r *ClusterReconciler) failedJoinClusterSet(ctx context.Context, cluster *clusterv1alpha1.Cluster, err string) (ctrl.Result, error) {
 	metautil.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
 		Type:               string(clusterv1alpha1.ClusterManaged),
@@ -482,11 +688,115 @@ func (r *ClusterReconciler) failedJoinClusterSet(ctx context.Context, cluster *c
 	return ctrl.Result{}, nil
 }
 
+// secretToClusters enqueues the Clusters which reference the Secret as kubeconfig
+func (r *ClusterReconciler) secretToClusters(obj client.Object) []reconcile.Request {
+	clusters := &clusterv1alpha1.ClusterList{}
+	if err := r.List(context.TODO(), clusters); err != nil {
+		klog.Errorf("Failed to list Clusters, %s", err)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, c := range clusters.Items {
+		ref := c.Spec.KubeconfigSecretRef
+		if ref == nil {
+			continue
+		}
+
+		if key, err := kubeconfigSecretKey(ref); err != nil || key != client.ObjectKeyFromObject(obj) {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: c.Name}})
+	}
+
+	return requests
+}
+
+// updateProbeStatus writes the result of probing into Ready and GatewayReachable conditions, and Managed
+// reflects whether the control plane holds the Lease of the cluster once it joined the ClusterSet
+func (r *ClusterReconciler) updateProbeStatus(ctx context.Context, probeEvt *event.ClusterProbeEvent) error {
+	cluster := &clusterv1alpha1.Cluster{}
+	if err := r.Get(ctx, client.ObjectKey{Name: probeEvt.Geo.Name()}, cluster); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	metautil.SetStatusCondition(&cluster.Status.Conditions, probeCondition(
+		clusterv1alpha1.ClusterReady,
+		probeEvt.APIServerReachable,
+		"APIServerReachable",
+		"APIServerUnreachable",
+		probeEvt.APIServerError,
+		cluster.Generation,
+	))
+	metautil.SetStatusCondition(&cluster.Status.Conditions, probeCondition(
+		clusterv1alpha1.ClusterGatewayReachable,
+		probeEvt.GatewayReachable,
+		"GatewayReachable",
+		"GatewayUnreachable",
+		probeEvt.GatewayError,
+		cluster.Generation,
+	))
+	if managed := metautil.FindStatusCondition(cluster.Status.Conditions, string(clusterv1alpha1.ClusterManaged)); managed != nil && managed.Reason != "Failed" {
+		metautil.SetStatusCondition(&cluster.Status.Conditions, managedCondition(cluster, probeEvt.Active))
+	}
+	cluster.Status.LastProbeTime = &metav1.Time{Time: probeEvt.ProbeTime}
+
+	return r.Status().Update(ctx, cluster)
+}
+
+func probeCondition(conditionType clusterv1alpha1.ClusterConditionType, success bool, successReason, failedReason, errMsg string, generation int64) metav1.Condition {
+	if success {
+		return metav1.Condition{
+			Type:               string(conditionType),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			LastTransitionTime: metav1.Time{Time: time.Now()},
+			Reason:             successReason,
+			Message:            "Probe succeeded.",
+		}
+	}
+
+	return metav1.Condition{
+		Type:               string(conditionType),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             failedReason,
+		Message:            fmt.Sprintf("Probe failed: %s", errMsg),
+	}
+}
+
+// deletingPredicate passes the updates of Cluster which is being deleted
+func deletingPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetDeletionTimestamp() != nil
+	})
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.Add(r); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&clusterv1alpha1.Cluster{}).
+		// status is updated by probing periodically, it's not necessary to reconcile
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, deletingPredicate()),
+		)).
 		Owns(&corev1.Secret{}).
 		Owns(&appv1.Deployment{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToClusters),
+			// kubeconfig Secrets are only allowed in the namespace of ErieCanal
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetNamespace() == config.GetErieCanalNamespace()
+			})),
+		).
 		Complete(r)
 }
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"fmt"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/flomesh-io/ErieCanal/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+)
+
+// processServiceExportCreatedEvent records the export and resolves conflicts among the exports
+// of the same service across clusters
+func (r *ClusterReconciler) processServiceExportCreatedEvent(svcExportEvt *event.ServiceExportEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svcKey := r.exportKey(svcExportEvt)
+	if _, ok := r.exports[svcKey]; !ok {
+		r.exports[svcKey] = make(map[string]*event.ServiceExportEvent)
+	}
+	r.exports[svcKey][svcExportEvt.ClusterKey()] = svcExportEvt
+
+	r.resolveConflicts(svcKey)
+}
+
+// processServiceExportDeletedEvent forgets the export, the conflicts are resolved again as
+// the oldest export might change
+func (r *ClusterReconciler) processServiceExportDeletedEvent(svcExportEvt *event.ServiceExportEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svcKey := r.exportKey(svcExportEvt)
+	delete(r.exports[svcKey], svcExportEvt.ClusterKey())
+	if len(r.exports[svcKey]) == 0 {
+		delete(r.exports, svcKey)
+		return
+	}
+
+	r.resolveConflicts(svcKey)
+}
+
+// forgetExportsOfCluster forgets all exports of the cluster which leaves the ClusterSet
+func (r *ClusterReconciler) forgetExportsOfCluster(clusterKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for svcKey, exports := range r.exports {
+		if _, ok := exports[clusterKey]; !ok {
+			continue
+		}
+
+		delete(exports, clusterKey)
+		if len(exports) == 0 {
+			delete(r.exports, svcKey)
+			continue
+		}
+
+		r.resolveConflicts(svcKey)
+	}
+}
+
+// exportKey identifies the service which an export is imported as, exports of different namespaces are
+// the same service if the namespace mapping imports them into the same namespace. The mapping of control
+// plane is used by all clusters on import, so the key is the same in every importing cluster
+func (r *ClusterReconciler) exportKey(svcExportEvt *event.ServiceExportEvent) string {
+	mc := r.configStore.MeshConfig.GetConfig()
+	svcExp := svcExportEvt.ServiceExport
+
+	return client.ObjectKey{Namespace: mc.ImportNamespace(svcExp.Namespace), Name: svcExp.Name}.String()
+}
+
+// resolveConflicts follows the conflict resolution of KEP-1645: the properties of the oldest export win.
+// All exports are accepted, the conflicting properties of an export are replaced by the ones of the oldest
+// export. If there's any conflict, all exports of the service are marked as Conflict, otherwise the condition
+// is cleared.
+func (r *ClusterReconciler) resolveConflicts(svcKey string) {
+	exports := sortedExports(r.exports[svcKey])
+	if len(exports) == 0 {
+		return
+	}
+
+	winner := exports[0]
+	conflicts := make(map[string]string)
+	for _, export := range exports[1:] {
+		if field := conflictField(winner, export); field != "" {
+			conflicts[export.ClusterKey()] = field
+		}
+	}
+
+	message := ""
+	if len(conflicts) > 0 {
+		fields := mapset.NewSet[string]()
+		for _, field := range conflicts {
+			fields.Add(field)
+		}
+		sorted := fields.ToSlice()
+		sort.Strings(sorted)
+		message = conflictMessage(strings.Join(sorted, ", "), winner)
+		klog.Warningf("ServiceExport %s: %s", svcKey, message)
+	}
+
+	for _, export := range exports {
+		evt := &event.ServiceExportEvent{
+			Geo:           export.Geo,
+			ServiceExport: export.ServiceExport,
+			Service:       export.Service,
+		}
+
+		// all exports are marked as Conflict if there's any conflict
+		evt.Error = message
+		if field, ok := conflicts[export.ClusterKey()]; ok {
+			klog.V(5).Infof("[%s] ServiceExport %s conflicts on %s, will be accepted with properties of the oldest one", export.ClusterKey(), svcKey, field)
+			evt.Service = withOldestProperties(winner, export)
+			evt.Error = conflictMessage(field, winner)
+		} else {
+			klog.V(5).Infof("[%s] ServiceExport %s is valid, will be accepted", export.ClusterKey(), svcKey)
+		}
+
+		r.acceptServiceExport(evt)
+	}
+}
+
+func conflictMessage(field string, oldest *event.ServiceExportEvent) string {
+	return fmt.Sprintf("conflict on %s, the ServiceExport of cluster %s is used as it's the oldest one", field, oldest.ClusterKey())
+}
+
+// sortedExports sorts the exports by creation time, the oldest comes first,
+// cluster key breaks a tie
+func sortedExports(exports map[string]*event.ServiceExportEvent) []*event.ServiceExportEvent {
+	result := make([]*event.ServiceExportEvent, 0, len(exports))
+	for _, export := range exports {
+		result = append(result, export)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ti := result[i].ServiceExport.CreationTimestamp
+		tj := result[j].ServiceExport.CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+
+		return result[i].ClusterKey() < result[j].ClusterKey()
+	})
+
+	return result
+}
+
+// conflictField returns the field of service on which the export conflicts with the oldest one,
+// empty if no conflict
+func conflictField(oldest, export *event.ServiceExportEvent) string {
+	if oldest.Service == nil || export.Service == nil {
+		return ""
+	}
+
+	if isHeadless(oldest.Service) != isHeadless(export.Service) {
+		return "type"
+	}
+
+	// ports with the same number must have the same properties, others are merged
+	ports := make(map[int32]corev1.ServicePort)
+	for _, p := range oldest.Service.Spec.Ports {
+		ports[p.Port] = p
+	}
+	for _, p := range export.Service.Spec.Ports {
+		o, ok := ports[p.Port]
+		if !ok {
+			continue
+		}
+
+		if o.Name != p.Name || o.Protocol != p.Protocol || !equality.Semantic.DeepEqual(o.AppProtocol, p.AppProtocol) {
+			return "ports"
+		}
+	}
+
+	if oldest.Service.Spec.SessionAffinity != export.Service.Spec.SessionAffinity ||
+		!equality.Semantic.DeepEqual(oldest.Service.Spec.SessionAffinityConfig, export.Service.Spec.SessionAffinityConfig) {
+		return "sessionAffinity"
+	}
+
+	return ""
+}
+
+// withOldestProperties returns the service of export whose type, ports and session affinity are replaced
+// by the ones of the oldest export, so that every cluster imports the service alike
+func withOldestProperties(oldest, export *event.ServiceExportEvent) *corev1.Service {
+	svc := export.Service.DeepCopy()
+	if oldest.Service == nil || svc == nil {
+		return svc
+	}
+
+	switch {
+	case isHeadless(oldest.Service) && !isHeadless(svc):
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+		svc.Spec.ClusterIPs = []string{corev1.ClusterIPNone}
+	case !isHeadless(oldest.Service) && isHeadless(svc):
+		svc.Spec.ClusterIP = ""
+		svc.Spec.ClusterIPs = nil
+	}
+
+	ports := make(map[int32]corev1.ServicePort)
+	for _, p := range oldest.Service.Spec.Ports {
+		ports[p.Port] = p
+	}
+	for i, p := range svc.Spec.Ports {
+		if o, ok := ports[p.Port]; ok {
+			svc.Spec.Ports[i].Name = o.Name
+			svc.Spec.Ports[i].Protocol = o.Protocol
+			svc.Spec.Ports[i].AppProtocol = o.AppProtocol
+		}
+	}
+
+	svc.Spec.SessionAffinity = oldest.Service.Spec.SessionAffinity
+	svc.Spec.SessionAffinityConfig = oldest.Service.Spec.SessionAffinityConfig.DeepCopy()
+
+	return svc
+}
+
+func isHeadless(svc *corev1.Service) bool {
+	return svc.Spec.ClusterIP == corev1.ClusterIPNone
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var created = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newService(headless bool, affinity corev1.ServiceAffinity, ports ...corev1.ServicePort) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "demo"},
+		Spec: corev1.ServiceSpec{
+			Ports:           ports,
+			SessionAffinity: affinity,
+		},
+	}
+	if headless {
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+		svc.Spec.ClusterIPs = []string{corev1.ClusterIPNone}
+	}
+
+	return svc
+}
+
+func newExportEvent(t *testing.T, cluster string, age time.Duration, svc *corev1.Service) *event.ServiceExportEvent {
+	t.Helper()
+
+	geo, err := config.NewConnectorConfig("default", "default", "default", cluster, "", 0, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &event.ServiceExportEvent{
+		Geo: geo,
+		ServiceExport: &svcexpv1alpha1.ServiceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              svc.Name,
+				Namespace:         svc.Namespace,
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+		},
+		Service: svc,
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	http := corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	grpc := corev1.ServicePort{Name: "grpc", Protocol: corev1.ProtocolTCP, Port: 80}
+	metrics := corev1.ServicePort{Name: "metrics", Protocol: corev1.ProtocolTCP, Port: 9090}
+
+	type export struct {
+		cluster string
+		age     time.Duration
+		svc     *corev1.Service
+	}
+
+	testCases := []struct {
+		name    string
+		exports []export
+		// the conflicting field in the error of each cluster, empty if the export has no error
+		errors map[string]string
+	}{
+		{
+			name: "no conflict",
+			exports: []export{
+				{"cluster1", 2 * time.Hour, newService(false, corev1.ServiceAffinityNone, http)},
+				{"cluster2", time.Hour, newService(false, corev1.ServiceAffinityNone, http)},
+			},
+			errors: map[string]string{"cluster1": "", "cluster2": ""},
+		},
+		{
+			name: "different port numbers are merged",
+			exports: []export{
+				{"cluster1", 2 * time.Hour, newService(false, corev1.ServiceAffinityNone, http)},
+				{"cluster2", time.Hour, newService(false, corev1.ServiceAffinityNone, metrics)},
+			},
+			errors: map[string]string{"cluster1": "", "cluster2": ""},
+		},
+		{
+			name: "conflict on type",
+			exports: []export{
+				{"cluster1", 2 * time.Hour, newService(true, corev1.ServiceAffinityNone, http)},
+				{"cluster2", time.Hour, newService(false, corev1.ServiceAffinityNone, http)},
+			},
+			errors: map[string]string{"cluster1": "type", "cluster2": "type"},
+		},
+		{
+			name: "conflict on ports",
+			exports: []export{
+				{"cluster1", time.Hour, newService(false, corev1.ServiceAffinityNone, grpc)},
+				{"cluster2", 2 * time.Hour, newService(false, corev1.ServiceAffinityNone, http, metrics)},
+			},
+			errors: map[string]string{"cluster1": "ports", "cluster2": "ports"},
+		},
+		{
+			name: "conflict on session affinity",
+			exports: []export{
+				{"cluster1", 2 * time.Hour, newService(false, corev1.ServiceAffinityNone, http)},
+				{"cluster2", time.Hour, newService(false, corev1.ServiceAffinityClientIP, http)},
+				{"cluster3", time.Hour, newService(false, corev1.ServiceAffinityNone, http)},
+			},
+			errors: map[string]string{"cluster1": "sessionAffinity", "cluster2": "sessionAffinity", "cluster3": "sessionAffinity"},
+		},
+		{
+			name: "cluster key breaks a tie of creation time",
+			exports: []export{
+				{"cluster2", time.Hour, newService(false, corev1.ServiceAffinityClientIP, http)},
+				{"cluster1", time.Hour, newService(false, corev1.ServiceAffinityNone, http)},
+			},
+			errors: map[string]string{"cluster1": "sessionAffinity", "cluster2": "sessionAffinity"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			r := &ClusterReconciler{
+				broker:  event.NewBroker(stopCh),
+				exports: make(map[string]map[string]*event.ServiceExportEvent),
+			}
+			accepted := r.broker.GetMessageBus().Sub(string(event.ServiceExportAccepted))
+			defer r.broker.Unsub(r.broker.GetMessageBus(), accepted)
+
+			svcKey := "demo/web"
+			r.exports[svcKey] = make(map[string]*event.ServiceExportEvent)
+			for _, e := range tc.exports {
+				evt := newExportEvent(t, e.cluster, e.age, e.svc)
+				r.exports[svcKey][evt.ClusterKey()] = evt
+			}
+			oldest := sortedExports(r.exports[svcKey])[0]
+			r.resolveConflicts(svcKey)
+
+			results := make(map[string]*event.ServiceExportEvent)
+			timeout := time.After(10 * time.Second)
+			for n := len(tc.exports); n > 0; n-- {
+				select {
+				case msg := <-accepted:
+					evt := msg.(event.Message).NewObj.(*event.ServiceExportEvent)
+					results[evt.ClusterKey()] = evt
+				case <-timeout:
+					t.Fatalf("expected %d more accepted exports", n)
+				}
+			}
+
+			for _, e := range tc.exports {
+				evt, ok := results["default/default/default/"+e.cluster]
+				if !ok {
+					t.Fatalf("expected the export of %s to be accepted", e.cluster)
+				}
+				field := tc.errors[e.cluster]
+				if field == "" && evt.Error != "" {
+					t.Errorf("expected no error of %s, got %q", e.cluster, evt.Error)
+				}
+				if field != "" && !strings.Contains(evt.Error, "conflict on "+field) {
+					t.Errorf("expected conflict on %s of %s, got %q", field, e.cluster, evt.Error)
+				}
+				if conflict := conflictField(oldest, evt); conflict != "" {
+					t.Errorf("expected %s to be accepted with the oldest properties, got conflict on %s", e.cluster, conflict)
+				}
+			}
+		})
+	}
+}
+
+func TestWithOldestProperties(t *testing.T) {
+	http := corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	grpc := corev1.ServicePort{Name: "grpc", Protocol: corev1.ProtocolTCP, Port: 80}
+	metrics := corev1.ServicePort{Name: "metrics", Protocol: corev1.ProtocolTCP, Port: 9090}
+
+	testCases := []struct {
+		name     string
+		oldest   *corev1.Service
+		export   *corev1.Service
+		expected *corev1.Service
+	}{
+		{
+			name:     "headless oldest export",
+			oldest:   newService(true, corev1.ServiceAffinityNone, http),
+			export:   newService(false, corev1.ServiceAffinityNone, http),
+			expected: newService(true, corev1.ServiceAffinityNone, http),
+		},
+		{
+			name:     "ClusterIP oldest export",
+			oldest:   newService(false, corev1.ServiceAffinityNone, http),
+			export:   newService(true, corev1.ServiceAffinityNone, http),
+			expected: newService(false, corev1.ServiceAffinityNone, http),
+		},
+		{
+			name:     "ports with the same number take the oldest properties, others are kept",
+			oldest:   newService(false, corev1.ServiceAffinityNone, grpc),
+			export:   newService(false, corev1.ServiceAffinityNone, http, metrics),
+			expected: newService(false, corev1.ServiceAffinityNone, grpc, metrics),
+		},
+		{
+			name:     "session affinity of the oldest export",
+			oldest:   newService(false, corev1.ServiceAffinityClientIP, http),
+			export:   newService(false, corev1.ServiceAffinityNone, http),
+			expected: newService(false, corev1.ServiceAffinityClientIP, http),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldest := newExportEvent(t, "cluster1", 2*time.Hour, tc.oldest)
+			export := newExportEvent(t, "cluster2", time.Hour, tc.export)
+
+			actual := withOldestProperties(oldest, export)
+			if !reflect.DeepEqual(actual.Spec, tc.expected.Spec) {
+				t.Errorf("expected %+v, got %+v", tc.expected.Spec, actual.Spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	mapset "github.com/deckarep/golang-set/v2"
+	clusterv1alpha1 "github.com/flomesh-io/ErieCanal/apis/cluster/v1alpha1"
+	gtpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/globaltrafficpolicy/v1alpha1"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/cache"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metautil "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"strings"
+	"time"
+)
+
+// GlobalTrafficPolicyReconciler reconciles a GlobalTrafficPolicy object
+type GlobalTrafficPolicyReconciler struct {
+	client.Client
+	K8sAPI                  *kube.K8sAPI
+	Scheme                  *runtime.Scheme
+	Recorder                record.EventRecorder
+	ControlPlaneConfigStore *config.Store
+}
+
+// Reconcile resolves the targets of the GlobalTrafficPolicy against the ServiceImport with the same
+// namespace/name and reports them in status, the policy itself is applied to the service registry by
+// the local cluster connector, see pkg/cache.
+func (r *GlobalTrafficPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
+
+	globalTrafficPolicy := &gtpv1alpha1.GlobalTrafficPolicy{}
+	if err := r.Get(
+		ctx,
+		client.ObjectKey{Name: req.Name, Namespace: req.Namespace},
+		globalTrafficPolicy,
+	); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			klog.V(3).Info("[GlobalTrafficPolicy] GlobalTrafficPolicy resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		klog.Errorf("Failed to get GlobalTrafficPolicy, %#v", err)
+		return ctrl.Result{}, err
+	}
+
+	status := globalTrafficPolicy.Status.DeepCopy()
+	generation := globalTrafficPolicy.Generation
+
+	if err := validate(globalTrafficPolicy); err != nil {
+		setCondition(status, generation, gtpv1alpha1.GlobalTrafficPolicyAccepted, metav1.ConditionFalse, "Invalid", err.Error())
+	} else {
+		setCondition(status, generation, gtpv1alpha1.GlobalTrafficPolicyAccepted, metav1.ConditionTrue, "Accepted", "GlobalTrafficPolicy is accepted")
+	}
+
+	svcImport := &svcimpv1alpha1.ServiceImport{}
+	if err := r.Get(ctx, req.NamespacedName, svcImport); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get ServiceImport %s, %#v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		svcImport = nil
+	}
+
+	known, err := r.knownClusters(ctx, mc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	available, err := r.availableClusters(ctx, req.NamespacedName, svcImport, mc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	known = known.Union(available)
+
+	unresolved := make([]string, 0)
+	for _, t := range globalTrafficPolicy.Spec.Targets {
+		if !known.Contains(t.ClusterKey) {
+			unresolved = append(unresolved, t.ClusterKey)
+		}
+	}
+	if len(unresolved) > 0 {
+		setCondition(status, generation, gtpv1alpha1.GlobalTrafficPolicyResolvedTargets, metav1.ConditionFalse, "UnknownCluster", fmt.Sprintf("Unknown clusters: %s", strings.Join(unresolved, ", ")))
+	} else {
+		setCondition(status, generation, gtpv1alpha1.GlobalTrafficPolicyResolvedTargets, metav1.ConditionTrue, "Resolved", "All targets are resolved")
+	}
+
+	status.Targets = targetStatuses(globalTrafficPolicy, mc.ClusterKey(), available)
+
+	switch {
+	case svcImport == nil:
+		setCondition(status, generation, gtpv1alpha1.GlobalTrafficPolicyProgrammed, metav1.ConditionFalse, "ServiceImportNotFound", fmt.Sprintf("ServiceImport %s doesn't exist", req.NamespacedName))
+	case !hasActiveTarget(status.Targets):
+		setCondition(status, generation, gtpv1alpha1.GlobalTrafficPolicyProgrammed, metav1.ConditionFalse, "NoActiveTarget", "None of the targets has endpoints")
+	default:
+		setCondition(status, generation, gtpv1alpha1.GlobalTrafficPolicyProgrammed, metav1.ConditionTrue, "Programmed", fmt.Sprintf("GlobalTrafficPolicy is applied to ServiceImport %s", req.NamespacedName))
+	}
+
+	if equality.Semantic.DeepEqual(globalTrafficPolicy.Status, *status) {
+		return ctrl.Result{}, nil
+	}
+
+	globalTrafficPolicy.Status = *status
+	if err := r.Status().Update(ctx, globalTrafficPolicy); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func validate(gtp *gtpv1alpha1.GlobalTrafficPolicy) error {
+	keys := mapset.NewSet[string]()
+	for _, t := range gtp.Spec.Targets {
+		if keys.Contains(t.ClusterKey) {
+			return fmt.Errorf("duplicated target cluster %q", t.ClusterKey)
+		}
+		keys.Add(t.ClusterKey)
+	}
+
+	return nil
+}
+
+// knownClusters returns keys of all Cluster resources, they're only available in control plane
+func (r *GlobalTrafficPolicyReconciler) knownClusters(ctx context.Context, mc *config.MeshConfig) (mapset.Set[string], error) {
+	clusters := &clusterv1alpha1.ClusterList{}
+	if err := r.List(ctx, clusters); err != nil {
+		klog.Errorf("Failed to list Clusters, %#v", err)
+		return nil, err
+	}
+
+	result := mapset.NewSet[string](mc.ClusterKey())
+	for _, c := range clusters.Items {
+		result.Add(c.Key())
+	}
+
+	return result, nil
+}
+
+// availableClusters returns keys of the clusters which have endpoints of the service, it's the
+// same as what the local cluster connector builds service routes from.
+func (r *GlobalTrafficPolicyReconciler) availableClusters(ctx context.Context, key types.NamespacedName, svcImport *svcimpv1alpha1.ServiceImport, mc *config.MeshConfig) (mapset.Set[string], error) {
+	result := mapset.NewSet[string]()
+
+	if svcImport != nil {
+		for _, p := range svcImport.Spec.Ports {
+			for _, ep := range p.Endpoints {
+				result.Add(ep.ClusterKey)
+			}
+		}
+	}
+
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, key, svc); err != nil {
+		if errors.IsNotFound(err) {
+			return result, nil
+		}
+		klog.Errorf("Failed to get Service %s, %#v", key, err)
+		return nil, err
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		result.Add(mc.ClusterKey())
+		return result, nil
+	}
+
+	ep := &corev1.Endpoints{}
+	if err := r.Get(ctx, key, ep); err != nil {
+		if errors.IsNotFound(err) {
+			return result, nil
+		}
+		klog.Errorf("Failed to get Endpoints %s, %#v", key, err)
+		return nil, err
+	}
+
+	for _, subset := range ep.Subsets {
+		if len(subset.Addresses) > 0 {
+			result.Add(mc.ClusterKey())
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// targetStatuses returns status of targets in spec, followed by the ones which are not in spec but active
+func targetStatuses(gtp *gtpv1alpha1.GlobalTrafficPolicy, localKey string, available mapset.Set[string]) []gtpv1alpha1.TrafficTargetStatus {
+	resolved := cache.ResolveTargets(gtp, localKey, available)
+	active := make(map[string]cache.ResolvedTarget)
+	for _, rt := range resolved {
+		active[rt.ClusterKey] = rt
+	}
+
+	result := make([]gtpv1alpha1.TrafficTargetStatus, 0)
+	listed := mapset.NewSet[string]()
+	for _, t := range gtp.Spec.Targets {
+		if listed.Contains(t.ClusterKey) {
+			continue
+		}
+		listed.Add(t.ClusterKey)
+		result = append(result, targetStatus(t.ClusterKey, available, active))
+	}
+
+	for _, rt := range resolved {
+		if !listed.Contains(rt.ClusterKey) {
+			result = append(result, targetStatus(rt.ClusterKey, available, active))
+		}
+	}
+
+	return result
+}
+
+func targetStatus(clusterKey string, available mapset.Set[string], active map[string]cache.ResolvedTarget) gtpv1alpha1.TrafficTargetStatus {
+	status := gtpv1alpha1.TrafficTargetStatus{
+		ClusterKey: clusterKey,
+		Reachable:  available.Contains(clusterKey),
+	}
+
+	if rt, ok := active[clusterKey]; ok && status.Reachable {
+		status.Active = true
+		status.Weight = rt.Weight
+	}
+
+	return status
+}
+
+func hasActiveTarget(targets []gtpv1alpha1.TrafficTargetStatus) bool {
+	for _, t := range targets {
+		if t.Active {
+			return true
+		}
+	}
+
+	return false
+}
+
+func setCondition(status *gtpv1alpha1.GlobalTrafficPolicyStatus, generation int64, conditionType gtpv1alpha1.GlobalTrafficPolicyConditionType, conditionStatus metav1.ConditionStatus, reason, message string) {
+	metautil.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(conditionType),
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GlobalTrafficPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gtpv1alpha1.GlobalTrafficPolicy{}).
+		Watches(
+			&source.Kind{Type: &svcimpv1alpha1.ServiceImport{}},
+			handler.EnqueueRequestsFromMapFunc(r.sameNameGlobalTrafficPolicy),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Endpoints{}},
+			&handler.EnqueueRequestForObject{},
+			// Endpoints change frequently, only the ones of services having GlobalTrafficPolicy matter
+			builder.WithPredicates(
+				predicate.NewPredicateFuncs(r.hasGlobalTrafficPolicy),
+				endpointsChangedPredicate(),
+			),
+		).
+		Watches(
+			&source.Kind{Type: &clusterv1alpha1.Cluster{}},
+			handler.EnqueueRequestsFromMapFunc(r.allGlobalTrafficPolicies),
+		).
+		Complete(r)
+}
+
+// sameNameGlobalTrafficPolicy maps an object to the GlobalTrafficPolicy with the same namespace/name if it exists
+func (r *GlobalTrafficPolicyReconciler) sameNameGlobalTrafficPolicy(obj client.Object) []reconcile.Request {
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	if err := r.Get(context.Background(), key, &gtpv1alpha1.GlobalTrafficPolicy{}); err != nil {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: key}}
+}
+
+// hasGlobalTrafficPolicy returns true if the GlobalTrafficPolicy with the same namespace/name as the object exists
+func (r *GlobalTrafficPolicyReconciler) hasGlobalTrafficPolicy(obj client.Object) bool {
+	return len(r.sameNameGlobalTrafficPolicy(obj)) > 0
+}
+
+// endpointsChangedPredicate skips the updates of Endpoints which don't change the addresses or ports,
+// e.g. the annotations renewed by leader election
+func endpointsChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldEp, ok := e.ObjectOld.(*corev1.Endpoints)
+			if !ok {
+				return true
+			}
+			newEp, ok := e.ObjectNew.(*corev1.Endpoints)
+			if !ok {
+				return true
+			}
+
+			return !equality.Semantic.DeepEqual(oldEp.Subsets, newEp.Subsets)
+		},
+	}
+}
+
+// allGlobalTrafficPolicies maps a Cluster to all GlobalTrafficPolicies, as any of them may target the cluster
+func (r *GlobalTrafficPolicyReconciler) allGlobalTrafficPolicies(_ client.Object) []reconcile.Request {
+	var policies gtpv1alpha1.GlobalTrafficPolicyList
+	if err := r.List(context.Background(), &policies); err != nil {
+		klog.Error("error listing GlobalTrafficPolicies")
+		return nil
+	}
+
+	var reconciles []reconcile.Request
+	for _, gtp := range policies.Items {
+		reconciles = append(reconciles, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: gtp.Namespace,
+				Name:      gtp.Name,
+			},
+		})
+	}
+
+	return reconciles
+}
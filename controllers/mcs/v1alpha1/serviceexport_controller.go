@@ -0,0 +1,262 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"github.com/flomesh-io/ErieCanal/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	"time"
+)
+
+// ServiceExportReconciler translates upstream multicluster.x-k8s.io ServiceExports
+// to flomesh.io ServiceExports, and mirrors the conditions back
+type ServiceExportReconciler struct {
+	client.Client
+	K8sAPI                  *kube.K8sAPI
+	Scheme                  *runtime.Scheme
+	Recorder                record.EventRecorder
+	ControlPlaneConfigStore *config.Store
+}
+
+// Reconcile creates a flomesh.io ServiceExport with the same name for the upstream ServiceExport,
+// one HTTP rule per TCP port of the Service. A flomesh.io ServiceExport which is not created from
+// the upstream one is left untouched, only its conditions are mirrored.
+func (r *ServiceExportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	upstream := &mcsv1alpha1.ServiceExport{}
+	if err := r.Get(ctx, req.NamespacedName, upstream); err != nil {
+		if errors.IsNotFound(err) {
+			// the translated ServiceExport is garbage collected as it's owned by the upstream one
+			klog.V(3).Info("[MCS] upstream ServiceExport resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		klog.Errorf("Failed to get upstream ServiceExport, %#v", err)
+		return ctrl.Result{}, err
+	}
+
+	if upstream.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	export := &svcexpv1alpha1.ServiceExport{}
+	if err := r.Get(ctx, req.NamespacedName, export); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		export = nil
+	}
+
+	if export != nil && !metav1.IsControlledBy(export, upstream) {
+		klog.V(3).Infof("[MCS] ServiceExport %s is not created from the upstream one, mirrors its conditions only", req.NamespacedName)
+		return r.updateStatus(ctx, upstream, upstreamConditions(export))
+	}
+
+	// the translated ServiceExport would be rejected by admission
+	if mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig(); !mc.IsNamespaceExportable(req.Namespace) {
+		return r.updateStatus(ctx, upstream, []mcsv1alpha1.ServiceExportCondition{
+			newCondition(mcsv1alpha1.ServiceExportValid, metav1.ConditionFalse, "NamespaceNotAllowed", fmt.Sprintf("Namespace %s is not allowed to export services", req.Namespace)),
+		})
+	}
+
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, req.NamespacedName, svc); err != nil {
+		if errors.IsNotFound(err) {
+			return r.updateStatus(ctx, upstream, []mcsv1alpha1.ServiceExportCondition{
+				newCondition(mcsv1alpha1.ServiceExportValid, metav1.ConditionFalse, "ServiceNotFound", fmt.Sprintf("Service %s not found", req.NamespacedName)),
+			})
+		}
+		return ctrl.Result{}, err
+	}
+
+	export, err := r.translate(ctx, upstream, svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if export == nil {
+		return r.updateStatus(ctx, upstream, []mcsv1alpha1.ServiceExportCondition{
+			newCondition(mcsv1alpha1.ServiceExportValid, metav1.ConditionFalse, "NoTCPPort", fmt.Sprintf("Service %s has no TCP port to be exported", req.NamespacedName)),
+		})
+	}
+
+	return r.updateStatus(ctx, upstream, upstreamConditions(export))
+}
+
+// translate creates or updates the flomesh.io ServiceExport owned by the upstream one,
+// it returns nil if there's nothing to export
+func (r *ServiceExportReconciler) translate(ctx context.Context, upstream *mcsv1alpha1.ServiceExport, svc *corev1.Service) (*svcexpv1alpha1.ServiceExport, error) {
+	export := newServiceExport(upstream, svc)
+	if export == nil {
+		return nil, nil
+	}
+
+	if err := ctrl.SetControllerReference(upstream, export, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	if _, err := util.CreateOrUpdate(ctx, r.Client, export); err != nil {
+		klog.Errorf("Failed to translate upstream ServiceExport %s/%s, %s", upstream.Namespace, upstream.Name, err)
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (r *ServiceExportReconciler) updateStatus(ctx context.Context, upstream *mcsv1alpha1.ServiceExport, conditions []mcsv1alpha1.ServiceExportCondition) (ctrl.Result, error) {
+	// keeps the transition time if the status of condition doesn't change
+	for i, c := range conditions {
+		for _, existing := range upstream.Status.Conditions {
+			if existing.Type == c.Type && existing.Status == c.Status && c.Reason != nil && existing.Reason != nil && *existing.Reason == *c.Reason {
+				conditions[i].LastTransitionTime = existing.LastTransitionTime
+			}
+		}
+	}
+
+	if equality.Semantic.DeepEqual(upstream.Status.Conditions, conditions) {
+		return ctrl.Result{}, nil
+	}
+
+	upstream.Status.Conditions = conditions
+	if err := r.Status().Update(ctx, upstream); err != nil {
+		klog.Errorf("Failed to update status of upstream ServiceExport %s/%s, %#v", upstream.Namespace, upstream.Name, err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// newServiceExport builds a flomesh.io ServiceExport which exports all TCP ports of the Service.
+// A single port is exported under path /<namespace>/<name>, multiple ports are exported under
+// /<namespace>/<name>/<port>, the prefix is stripped by the gateway before forwarding.
+func newServiceExport(upstream *mcsv1alpha1.ServiceExport, svc *corev1.Service) *svcexpv1alpha1.ServiceExport {
+	ports := make([]int32, 0)
+	for _, p := range svc.Spec.Ports {
+		if p.Protocol == corev1.ProtocolTCP || p.Protocol == "" {
+			ports = append(ports, p.Port)
+		}
+	}
+
+	if len(ports) == 0 {
+		return nil
+	}
+
+	prefix := fmt.Sprintf("/%s/%s", svc.Namespace, svc.Name)
+	pathType := networkingv1.PathTypePrefix
+	rules := make([]svcexpv1alpha1.ServiceExportRule, 0)
+	rewrite := &svcexpv1alpha1.PathRewrite{From: fmt.Sprintf("^%s/?", prefix), To: "/"}
+	if len(ports) > 1 {
+		rewrite.From = fmt.Sprintf("^%s/[0-9]+/?", prefix)
+	}
+
+	for _, port := range ports {
+		path := prefix
+		if len(ports) > 1 {
+			path = fmt.Sprintf("%s/%d", prefix, port)
+		}
+		rules = append(rules, svcexpv1alpha1.ServiceExportRule{
+			PortNumber: port,
+			Path:       path,
+			PathType:   &pathType,
+		})
+	}
+
+	return &svcexpv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: upstream.Namespace,
+			Name:      upstream.Name,
+		},
+		Spec: svcexpv1alpha1.ServiceExportSpec{
+			PathRewrite: rewrite,
+			Rules:       rules,
+		},
+	}
+}
+
+// upstreamConditions converts the conditions of flomesh.io ServiceExport to the upstream ones
+func upstreamConditions(export *svcexpv1alpha1.ServiceExport) []mcsv1alpha1.ServiceExportCondition {
+	conditions := make([]mcsv1alpha1.ServiceExportCondition, 0)
+	for _, c := range export.Status.Conditions {
+		var t mcsv1alpha1.ServiceExportConditionType
+		switch svcexpv1alpha1.ServiceExportConditionType(c.Type) {
+		case svcexpv1alpha1.ServiceExportValid:
+			t = mcsv1alpha1.ServiceExportValid
+		case svcexpv1alpha1.ServiceExportConflict:
+			t = mcsv1alpha1.ServiceExportConflict
+		default:
+			continue
+		}
+
+		condition := newCondition(t, c.Status, c.Reason, c.Message)
+		condition.LastTransitionTime = c.LastTransitionTime.DeepCopy()
+		conditions = append(conditions, condition)
+	}
+
+	return conditions
+}
+
+func newCondition(t mcsv1alpha1.ServiceExportConditionType, status metav1.ConditionStatus, reason, message string) mcsv1alpha1.ServiceExportCondition {
+	return mcsv1alpha1.ServiceExportCondition{
+		Type:               t,
+		Status:             corev1.ConditionStatus(status),
+		LastTransitionTime: &metav1.Time{Time: time.Now()},
+		Reason:             &reason,
+		Message:            &message,
+	}
+}
+
+// toServiceExport enqueues the upstream ServiceExport with the same name as the Service or
+// flomesh.io ServiceExport, regardless of whether the flomesh.io ServiceExport is owned by it
+func (r *ServiceExportReconciler) toServiceExport(obj client.Object) []reconcile.Request {
+	upstream := &mcsv1alpha1.ServiceExport{}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(obj), upstream); err != nil {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(obj)}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceExportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mcsv1alpha1.ServiceExport{}).
+		Watches(
+			&source.Kind{Type: &svcexpv1alpha1.ServiceExport{}},
+			handler.EnqueueRequestsFromMapFunc(r.toServiceExport),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			handler.EnqueueRequestsFromMapFunc(r.toServiceExport),
+		).
+		Complete(r)
+}
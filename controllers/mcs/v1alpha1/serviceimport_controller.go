@@ -0,0 +1,150 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"context"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"github.com/flomesh-io/ErieCanal/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	"sort"
+)
+
+// ServiceImportReconciler mirrors flomesh.io ServiceImports as upstream multicluster.x-k8s.io ServiceImports
+type ServiceImportReconciler struct {
+	client.Client
+	K8sAPI                  *kube.K8sAPI
+	Scheme                  *runtime.Scheme
+	Recorder                record.EventRecorder
+	ControlPlaneConfigStore *config.Store
+}
+
+// Reconcile creates or updates the upstream ServiceImport with the same name, it's owned by
+// the flomesh.io ServiceImport and is garbage collected along with it. An upstream ServiceImport
+// managed by others is never overwritten.
+func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	svcImport := &svcimpv1alpha1.ServiceImport{}
+	if err := r.Get(ctx, req.NamespacedName, svcImport); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(3).Info("[MCS] ServiceImport resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		klog.Errorf("Failed to get ServiceImport, %#v", err)
+		return ctrl.Result{}, err
+	}
+
+	if svcImport.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	existing := &mcsv1alpha1.ServiceImport{}
+	if err := r.Get(ctx, req.NamespacedName, existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	} else if !metav1.IsControlledBy(existing, svcImport) {
+		klog.Warningf("Upstream ServiceImport %s is not managed by ErieCanal, skip mirroring", req.NamespacedName)
+		r.Recorder.Eventf(svcImport, corev1.EventTypeWarning, "MirrorSkipped", "Upstream ServiceImport %s is managed by others", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	upstream := newUpstreamServiceImport(svcImport)
+	if err := ctrl.SetControllerReference(svcImport, upstream, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if _, err := util.CreateOrUpdate(ctx, r.Client, upstream); err != nil {
+		klog.Errorf("Failed to mirror ServiceImport %s, %s", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	clusters := upstreamClusters(svcImport)
+	if equality.Semantic.DeepEqual(upstream.Status.Clusters, clusters) {
+		return ctrl.Result{}, nil
+	}
+
+	upstream.Status.Clusters = clusters
+	if err := r.Status().Update(ctx, upstream); err != nil {
+		klog.Errorf("Failed to update status of upstream ServiceImport %s, %#v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func newUpstreamServiceImport(svcImport *svcimpv1alpha1.ServiceImport) *mcsv1alpha1.ServiceImport {
+	ports := make([]mcsv1alpha1.ServicePort, 0)
+	for _, p := range svcImport.Spec.Ports {
+		ports = append(ports, mcsv1alpha1.ServicePort{
+			Name:        p.Name,
+			Protocol:    p.Protocol,
+			AppProtocol: p.AppProtocol,
+			Port:        p.Port,
+		})
+	}
+
+	return &mcsv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: svcImport.Namespace,
+			Name:      svcImport.Name,
+		},
+		Spec: mcsv1alpha1.ServiceImportSpec{
+			Ports:                 ports,
+			IPs:                   svcImport.Spec.IPs,
+			Type:                  mcsv1alpha1.ServiceImportType(svcImport.Spec.Type),
+			SessionAffinity:       svcImport.Spec.SessionAffinity,
+			SessionAffinityConfig: svcImport.Spec.SessionAffinityConfig,
+		},
+	}
+}
+
+// upstreamClusters converts the cluster keys of exporting clusters to cluster names, sorted
+func upstreamClusters(svcImport *svcimpv1alpha1.ServiceImport) []mcsv1alpha1.ClusterStatus {
+	names := make(map[string]bool)
+	for _, c := range svcImport.Status.Clusters {
+		names[svcimpv1alpha1.ClusterName(c.Cluster)] = true
+	}
+
+	clusters := make([]mcsv1alpha1.ClusterStatus, 0)
+	for name := range names {
+		clusters = append(clusters, mcsv1alpha1.ClusterStatus{Cluster: name})
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Cluster < clusters[j].Cluster
+	})
+
+	return clusters
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceImportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&svcimpv1alpha1.ServiceImport{}).
+		Owns(&mcsv1alpha1.ServiceImport{}).
+		Complete(r)
+}
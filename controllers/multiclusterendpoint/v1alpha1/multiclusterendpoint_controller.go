@@ -0,0 +1,290 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	mcev1alpha1 "github.com/flomesh-io/ErieCanal/apis/multiclusterendpoint/v1alpha1"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metautil "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"time"
+)
+
+// finalizerName is added to MultiClusterEndpoints, the endpoint is detached from the ServiceImport before deletion
+const finalizerName = "multicluster.flomesh.io/endpoint-cleanup"
+
+// MultiClusterEndpointReconciler reconciles a MultiClusterEndpoint object
+type MultiClusterEndpointReconciler struct {
+	client.Client
+	K8sAPI                  *kube.K8sAPI
+	Scheme                  *runtime.Scheme
+	Recorder                record.EventRecorder
+	ControlPlaneConfigStore *config.Store
+}
+
+// Reconcile merges the endpoint into the port of the ServiceImport with the same namespace, the service
+// registry of the cluster is built from the ServiceImport by the local cluster connector, see pkg/cache.
+// The endpoints of other clusters are kept by cluster connectors while importing the ServiceExports.
+func (r *MultiClusterEndpointReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	mce := &mcev1alpha1.MultiClusterEndpoint{}
+	if err := r.Get(ctx, req.NamespacedName, mce); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(3).Info("[MultiClusterEndpoint] MultiClusterEndpoint resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		klog.Errorf("Failed to get MultiClusterEndpoint, %#v", err)
+		return ctrl.Result{}, err
+	}
+
+	if mce.DeletionTimestamp != nil {
+		return r.detachAndRemoveFinalizer(ctx, mce)
+	}
+
+	if !controllerutil.ContainsFinalizer(mce, finalizerName) {
+		controllerutil.AddFinalizer(mce, finalizerName)
+		if err := r.Update(ctx, mce); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	status := mce.Status.DeepCopy()
+	desired := desiredEndpoint(mce)
+
+	// the spec is changed, detach the endpoint attached before
+	if status.Attached != nil && !equality.Semantic.DeepEqual(*status.Attached, desired) {
+		if err := r.detach(ctx, mce.Namespace, status.Attached); err != nil {
+			return ctrl.Result{}, err
+		}
+		status.Attached = nil
+	}
+
+	reason, message, err := r.attach(ctx, mce.Namespace, desired)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if reason == "" {
+		status.Attached = &desired
+		setCondition(status, mce.Generation, metav1.ConditionTrue, "Attached", fmt.Sprintf("Endpoint is attached to port %d of ServiceImport %s/%s", desired.Port, mce.Namespace, desired.ServiceName))
+	} else {
+		status.Attached = nil
+		setCondition(status, mce.Generation, metav1.ConditionFalse, reason, message)
+	}
+
+	if equality.Semantic.DeepEqual(mce.Status, *status) {
+		return ctrl.Result{}, nil
+	}
+
+	mce.Status = *status
+	if err := r.Status().Update(ctx, mce); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *MultiClusterEndpointReconciler) detachAndRemoveFinalizer(ctx context.Context, mce *mcev1alpha1.MultiClusterEndpoint) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(mce, finalizerName) {
+		return ctrl.Result{}, nil
+	}
+
+	if mce.Status.Attached != nil {
+		if err := r.detach(ctx, mce.Namespace, mce.Status.Attached); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(mce, finalizerName)
+	if err := r.Update(ctx, mce); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// attach adds the endpoint to the port of ServiceImport if it's not there, a non-empty reason is
+// returned if it cannot be attached
+func (r *MultiClusterEndpointReconciler) attach(ctx context.Context, namespace string, ep mcev1alpha1.AttachedEndpoint) (string, string, error) {
+	svcImport := &svcimpv1alpha1.ServiceImport{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ep.ServiceName}, svcImport); err != nil {
+		if errors.IsNotFound(err) {
+			return "ServiceImportNotFound", fmt.Sprintf("ServiceImport %s/%s doesn't exist", namespace, ep.ServiceName), nil
+		}
+		return "", "", err
+	}
+
+	if svcImport.DeletionTimestamp != nil {
+		return "ServiceImportDeleting", fmt.Sprintf("ServiceImport %s/%s is being deleted", namespace, ep.ServiceName), nil
+	}
+
+	mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
+	if ep.ClusterKey == mc.ClusterKey() {
+		return "InvalidClusterKey", fmt.Sprintf("ClusterKey %s is the key of this cluster", ep.ClusterKey), nil
+	}
+
+	for i, p := range svcImport.Spec.Ports {
+		if p.Port != ep.Port {
+			continue
+		}
+
+		for _, existing := range p.Endpoints {
+			if isEndpoint(existing, ep) {
+				return "", "", nil
+			}
+		}
+
+		svcImport.Spec.Ports[i].Endpoints = append(svcImport.Spec.Ports[i].Endpoints, svcimpv1alpha1.Endpoint{
+			ClusterKey: ep.ClusterKey,
+			Target:     serviceImportTarget(ep.Target),
+		})
+		if err := r.Update(ctx, svcImport); err != nil {
+			return "", "", err
+		}
+
+		return "", "", nil
+	}
+
+	return "PortNotFound", fmt.Sprintf("ServiceImport %s/%s has no port %d", namespace, ep.ServiceName, ep.Port), nil
+}
+
+// detach removes the endpoint from the ServiceImport if it's there
+func (r *MultiClusterEndpointReconciler) detach(ctx context.Context, namespace string, ep *mcev1alpha1.AttachedEndpoint) error {
+	svcImport := &svcimpv1alpha1.ServiceImport{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ep.ServiceName}, svcImport); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	found := false
+	for i, p := range svcImport.Spec.Ports {
+		if p.Port != ep.Port {
+			continue
+		}
+
+		endpoints := make([]svcimpv1alpha1.Endpoint, 0)
+		for _, existing := range p.Endpoints {
+			if isEndpoint(existing, *ep) {
+				found = true
+				continue
+			}
+			endpoints = append(endpoints, existing)
+		}
+		svcImport.Spec.Ports[i].Endpoints = endpoints
+	}
+
+	if !found {
+		return nil
+	}
+
+	klog.V(5).Infof("Detaching endpoint %s of cluster %s from ServiceImport %s/%s", ep.Target.Host, ep.ClusterKey, namespace, ep.ServiceName)
+	return r.Update(ctx, svcImport)
+}
+
+func desiredEndpoint(mce *mcev1alpha1.MultiClusterEndpoint) mcev1alpha1.AttachedEndpoint {
+	target := mce.Spec.Target
+	if target.Host == "" {
+		target.Host = target.IP
+	}
+
+	return mcev1alpha1.AttachedEndpoint{
+		ServiceName: mce.Spec.ServiceName,
+		Port:        mce.Spec.Port,
+		ClusterKey:  mce.Spec.ClusterKey,
+		Target:      target,
+	}
+}
+
+func isEndpoint(existing svcimpv1alpha1.Endpoint, ep mcev1alpha1.AttachedEndpoint) bool {
+	return existing.ClusterKey == ep.ClusterKey &&
+		equality.Semantic.DeepEqual(existing.Target, serviceImportTarget(ep.Target))
+}
+
+// serviceImportTarget converts the target of MultiClusterEndpoint, the endpoint isn't a gateway of
+// cluster, so it has no gateway TLS settings
+func serviceImportTarget(target mcev1alpha1.Target) svcimpv1alpha1.Target {
+	return svcimpv1alpha1.Target{
+		Host: target.Host,
+		IP:   target.IP,
+		IPs:  target.IPs,
+		Port: target.Port,
+		Path: target.Path,
+	}
+}
+
+func setCondition(status *mcev1alpha1.MultiClusterEndpointStatus, generation int64, conditionStatus metav1.ConditionStatus, reason, message string) {
+	// keeps the transition time if nothing changes, avoid updating status again and again
+	if existing := metautil.FindStatusCondition(status.Conditions, string(mcev1alpha1.MultiClusterEndpointAttached)); existing != nil &&
+		existing.Status == conditionStatus && existing.Reason == reason && existing.Message == message && existing.ObservedGeneration == generation {
+		return
+	}
+
+	metautil.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(mcev1alpha1.MultiClusterEndpointAttached),
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MultiClusterEndpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&mcev1alpha1.MultiClusterEndpoint{}).
+		Watches(
+			&source.Kind{Type: &svcimpv1alpha1.ServiceImport{}},
+			handler.EnqueueRequestsFromMapFunc(r.serviceImportToMultiClusterEndpoints),
+		).
+		Complete(r)
+}
+
+// serviceImportToMultiClusterEndpoints maps a ServiceImport to the MultiClusterEndpoints attached to it, the endpoints
+// are attached again once the ServiceImport is recreated or its ports are replaced by cluster connectors
+func (r *MultiClusterEndpointReconciler) serviceImportToMultiClusterEndpoints(obj client.Object) []reconcile.Request {
+	list := &mcev1alpha1.MultiClusterEndpointList{}
+	if err := r.List(context.Background(), list, client.InNamespace(obj.GetNamespace())); err != nil {
+		klog.Errorf("Failed to list MultiClusterEndpoints in namespace %s, %#v", obj.GetNamespace(), err)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, mce := range list.Items {
+		if mce.Spec.ServiceName == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&mce)})
+		}
+	}
+
+	return requests
+}
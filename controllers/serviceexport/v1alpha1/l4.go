@@ -0,0 +1,213 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metautil "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+)
+
+// exportL4 allocates a dedicated gateway port for each exported port of the Service, the ports are
+// published in status and ingress-pipy proxies the TCP/UDP traffic of them to the Service
+func (r *ServiceExportReconciler) exportL4(ctx context.Context, req ctrl.Request, export *svcexpv1alpha1.ServiceExport, svc *corev1.Service) (ctrl.Result, error) {
+	mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
+	if !mc.Ingress.L4.Enabled {
+		return r.failedExportL4(ctx, export, "L4 export is disabled, please enable ingress.l4 in MeshConfig")
+	}
+
+	for _, rule := range export.Spec.Rules {
+		if !hasServicePort(svc, rule.PortNumber, rule.GetProtocol()) {
+			return r.failedExportL4(ctx, export, fmt.Sprintf("Service %s has no port %d/%s", req.NamespacedName, rule.PortNumber, rule.GetProtocol()))
+		}
+	}
+
+	// the export may be switched from HTTP mode, remove the Ingress of it
+	if err := r.deleteIngress(ctx, export); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ports, err := r.allocateGatewayPorts(ctx, export, mc.Ingress.L4)
+	if err != nil {
+		return r.failedExportL4(ctx, export, err.Error())
+	}
+	export.Status.GatewayPorts = ports
+
+	return r.successExport(ctx, req, export, svc)
+}
+
+func (r *ServiceExportReconciler) failedExportL4(ctx context.Context, export *svcexpv1alpha1.ServiceExport, message string) (ctrl.Result, error) {
+	export.Status.GatewayPorts = nil
+	metautil.SetStatusCondition(&export.Status.Conditions, metav1.Condition{
+		Type:               string(svcexpv1alpha1.ServiceExportValid),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: export.Generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             "Failed",
+		Message:            message,
+	})
+
+	if err := r.Status().Update(ctx, export); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// stop processing, it's retried once the export or MeshConfig is changed
+	return ctrl.Result{}, nil
+}
+
+func (r *ServiceExportReconciler) deleteIngress(ctx context.Context, export *svcexpv1alpha1.ServiceExport) error {
+	ing := &networkingv1.Ingress{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: export.Namespace, Name: fmt.Sprintf("svcexp-ing-%s", export.Name)}, ing); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !metav1.IsControlledBy(ing, export) {
+		return nil
+	}
+
+	klog.V(5).Infof("Deleting Ingress %s/%s of L4 ServiceExport", ing.Namespace, ing.Name)
+	if err := r.Delete(ctx, ing); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// allocateGatewayPorts keeps the gateway ports already allocated to the export and allocates the lowest
+// free ports in range for the others. If two exports hold the same port, the older one keeps it.
+func (r *ServiceExportReconciler) allocateGatewayPorts(ctx context.Context, export *svcexpv1alpha1.ServiceExport, l4 config.L4) ([]svcexpv1alpha1.ServiceExportGatewayPort, error) {
+	exports := &svcexpv1alpha1.ServiceExportList{}
+	if err := r.List(ctx, exports); err != nil {
+		return nil, err
+	}
+
+	// ports held by other exports, the value is true if the holder is older than this export
+	held := make(map[int32]bool)
+	for i := range exports.Items {
+		other := &exports.Items[i]
+		if other.Namespace == export.Namespace && other.Name == export.Name {
+			continue
+		}
+		for _, p := range other.Status.GatewayPorts {
+			held[p.GatewayPort] = held[p.GatewayPort] || isOlder(other, export)
+		}
+	}
+
+	inRange := func(port int32) bool {
+		return port >= l4.MinPort && port <= l4.MaxPort
+	}
+
+	ports := make([]svcexpv1alpha1.ServiceExportGatewayPort, 0)
+	allocated := make(map[int32]bool)
+	for _, rule := range export.Spec.Rules {
+		protocol := rule.GetProtocol()
+		port := export.GatewayPort(rule.PortNumber, protocol)
+		if port == 0 || !inRange(port) || held[port] || allocated[port] {
+			port = 0
+			for p := l4.MinPort; p <= l4.MaxPort && p > 0; p++ {
+				if _, ok := held[p]; !ok && !allocated[p] {
+					port = p
+					break
+				}
+			}
+		}
+
+		if port == 0 {
+			return nil, fmt.Errorf("no free gateway port in range [%d, %d] for port %d/%s", l4.MinPort, l4.MaxPort, rule.PortNumber, protocol)
+		}
+
+		allocated[port] = true
+		ports = append(ports, svcexpv1alpha1.ServiceExportGatewayPort{
+			PortNumber:  rule.PortNumber,
+			Protocol:    protocol,
+			GatewayPort: port,
+		})
+	}
+
+	return ports, nil
+}
+
+// exportsSharingGatewayPorts maps a ServiceExport to the other exports holding any of its gateway ports.
+// Exports reconciled concurrently may allocate the same port, once the status of one is updated the
+// others are reconciled again, so that the newer ones re-allocate.
+func (r *ServiceExportReconciler) exportsSharingGatewayPorts(obj client.Object) []reconcile.Request {
+	export, ok := obj.(*svcexpv1alpha1.ServiceExport)
+	if !ok || len(export.Status.GatewayPorts) == 0 {
+		return nil
+	}
+
+	ports := make(map[int32]bool)
+	for _, p := range export.Status.GatewayPorts {
+		ports[p.GatewayPort] = true
+	}
+
+	exports := &svcexpv1alpha1.ServiceExportList{}
+	if err := r.List(context.TODO(), exports); err != nil {
+		klog.Errorf("Failed to list ServiceExports: %s", err)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range exports.Items {
+		other := &exports.Items[i]
+		if other.Namespace == export.Namespace && other.Name == export.Name {
+			continue
+		}
+
+		for _, p := range other.Status.GatewayPorts {
+			if ports[p.GatewayPort] {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(other)})
+				break
+			}
+		}
+	}
+
+	return requests
+}
+
+// isOlder returns true if a is created before b, name is compared if they're created at the same time
+func isOlder(a, b *svcexpv1alpha1.ServiceExport) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+
+	return client.ObjectKeyFromObject(a).String() < client.ObjectKeyFromObject(b).String()
+}
+
+func hasServicePort(svc *corev1.Service, port int32, protocol corev1.Protocol) bool {
+	for _, p := range svc.Spec.Ports {
+		if p.Port == port && p.Protocol == protocol {
+			return true
+		}
+	}
+
+	return false
+}
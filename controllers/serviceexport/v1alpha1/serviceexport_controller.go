@@ -33,11 +33,15 @@ import (
 	metautil "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"time"
 )
 
@@ -88,6 +92,11 @@ func (r *ServiceExportReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		export.Status.Conditions = make([]metav1.Condition, 0)
 	}
 
+	mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
+	if !mc.IsNamespaceExportable(export.Namespace) {
+		return r.namespaceNotExportable(ctx, export)
+	}
+
 	svc := &corev1.Service{}
 	if err := r.Get(ctx, req.NamespacedName, svc); err != nil {
 		// the service doesn't exist
@@ -110,6 +119,11 @@ func (r *ServiceExportReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return r.unsupportedServiceType(ctx, req, export)
 	}
 
+	// L4 export is proxied by a dedicated gateway port instead of Ingress paths
+	if export.IsL4() {
+		return r.exportL4(ctx, req, export, svc)
+	}
+
 	// Find and compare path from ingress
 	ingList := &networkingv1.IngressList{}
 	if err := r.List(ctx, ingList, client.InNamespace(corev1.NamespaceAll)); err != nil {
@@ -123,7 +137,7 @@ func (r *ServiceExportReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			}
 			for _, rule := range ing.Spec.Rules {
 				for _, path := range rule.HTTP.Paths {
-					if path.Path == er.Path && string(*path.PathType) == string(*er.PathType) {
+					if er.PathType != nil && path.Path == er.Path && string(*path.PathType) == string(*er.PathType) {
 						return r.pathConflicts(ctx, export, path, ing)
 					}
 				}
@@ -151,7 +165,7 @@ func (r *ServiceExportReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 				return ctrl.Result{}, err
 			}
 
-			return r.successExport(ctx, req, export)
+			return r.successExport(ctx, req, export, svc)
 		}
 
 		return ctrl.Result{}, err
@@ -195,7 +209,7 @@ func (r *ServiceExportReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
-	return r.successExport(ctx, req, export)
+	return r.successExport(ctx, req, export, svc)
 }
 
 func (r *ServiceExportReconciler) nonexistService(ctx context.Context, req ctrl.Request, export *svcexpv1alpha1.ServiceExport) (ctrl.Result, error) {
@@ -215,6 +229,30 @@ func (r *ServiceExportReconciler) nonexistService(ctx context.Context, req ctrl.
 	return ctrl.Result{}, nil
 }
 
+func (r *ServiceExportReconciler) namespaceNotExportable(ctx context.Context, export *svcexpv1alpha1.ServiceExport) (ctrl.Result, error) {
+	// the policy may be changed after the export is admitted, stop routing to it
+	if err := r.deleteIngress(ctx, export); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	export.Status.GatewayPorts = nil
+	metautil.SetStatusCondition(&export.Status.Conditions, metav1.Condition{
+		Type:               string(svcexpv1alpha1.ServiceExportValid),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: export.Generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             "Failed",
+		Message:            fmt.Sprintf("Namespace %s is not allowed to export services.", export.Namespace),
+	})
+
+	if err := r.Status().Update(ctx, export); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// stop processing
+	return ctrl.Result{}, nil
+}
+
 func (r *ServiceExportReconciler) failedGetService(ctx context.Context, req ctrl.Request, export *svcexpv1alpha1.ServiceExport, err error) (ctrl.Result, error) {
 	// unknown errors
 	metautil.SetStatusCondition(&export.Status.Conditions, metav1.Condition{
@@ -374,7 +412,16 @@ func ingressPaths(export *svcexpv1alpha1.ServiceExport) []networkingv1.HTTPIngre
 	return paths
 }
 
-func (r *ServiceExportReconciler) successExport(ctx context.Context, req ctrl.Request, export *svcexpv1alpha1.ServiceExport) (ctrl.Result, error) {
+func (r *ServiceExportReconciler) successExport(ctx context.Context, req ctrl.Request, export *svcexpv1alpha1.ServiceExport, svc *corev1.Service) (ctrl.Result, error) {
+	hostnames, err := r.podHostnames(ctx, svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	export.Status.Hostnames = hostnames
+	if !export.IsL4() {
+		export.Status.GatewayPorts = nil
+	}
+
 	// service is exported successfully
 	metautil.SetStatusCondition(&export.Status.Conditions, metav1.Condition{
 		Type:               string(svcexpv1alpha1.ServiceExportValid),
@@ -392,10 +439,60 @@ func (r *ServiceExportReconciler) successExport(ctx context.Context, req ctrl.Re
 	return ctrl.Result{}, nil
 }
 
+// podHostnames returns the sorted hostnames of ready Pods of a headless Service,
+// Pods without hostname cannot be addressed individually and are ignored
+func (r *ServiceExportReconciler) podHostnames(ctx context.Context, svc *corev1.Service) ([]string, error) {
+	if svc.Spec.ClusterIP != corev1.ClusterIPNone {
+		return nil, nil
+	}
+
+	ep := &corev1.Endpoints{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(svc), ep); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	hostnames := sets.NewString()
+	for _, subset := range ep.Subsets {
+		for _, addr := range subset.Addresses {
+			if addr.Hostname != "" {
+				hostnames.Insert(addr.Hostname)
+			}
+		}
+	}
+
+	if hostnames.Len() == 0 {
+		return nil, nil
+	}
+
+	return hostnames.List(), nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceExportReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&svcexpv1alpha1.ServiceExport{}).
 		Owns(&networkingv1.Ingress{}).
+		Watches(
+			&source.Kind{Type: &corev1.Endpoints{}},
+			handler.EnqueueRequestsFromMapFunc(r.endpointsToServiceExport),
+		).
+		Watches(
+			&source.Kind{Type: &svcexpv1alpha1.ServiceExport{}},
+			handler.EnqueueRequestsFromMapFunc(r.exportsSharingGatewayPorts),
+		).
 		Complete(r)
 }
+
+// endpointsToServiceExport maps Endpoints to the ServiceExport with the same namespace/name if it exists,
+// as hostnames of Pods are published in status of the ServiceExport of a headless Service
+func (r *ServiceExportReconciler) endpointsToServiceExport(obj client.Object) []reconcile.Request {
+	key := client.ObjectKeyFromObject(obj)
+	if err := r.Get(context.Background(), key, &svcexpv1alpha1.ServiceExport{}); err != nil {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: key}}
+}
@@ -19,16 +19,32 @@ package v1alpha1
 import (
 	"context"
 	_ "embed"
+	"fmt"
+	mapset "github.com/deckarep/golang-set/v2"
 	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
 	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/ipam"
 	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"github.com/flomesh-io/ErieCanal/pkg/outlier"
+	"github.com/flomesh-io/ErieCanal/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"net"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sort"
+	"strconv"
+	"time"
 )
 
 // ServiceImportReconciler reconciles a ServiceImport object
@@ -38,31 +54,31 @@ type ServiceImportReconciler struct {
 	Scheme                  *runtime.Scheme
 	Recorder                record.EventRecorder
 	ControlPlaneConfigStore *config.Store
+	allocator               *ipam.Allocator
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the ServiceImport closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the ServiceImport object against the actual ServiceImport state, and then
-// perform operations to make the ServiceImport state reflect the state specified by
-// the user.
+// It derives a ClusterIP Service with Endpoints from the ServiceImport, and maintains
+// status.clusters from the endpoints of the exporting clusters.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
 func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	//mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
-
-	ServiceImport := &svcimpv1alpha1.ServiceImport{}
+	svcImport := &svcimpv1alpha1.ServiceImport{}
 	if err := r.Get(
 		ctx,
 		client.ObjectKey{Name: req.Name, Namespace: req.Namespace},
-		ServiceImport,
+		svcImport,
 	); err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			klog.V(3).Info("[ServiceImport] ServiceImport resource not found. Ignoring since object must be deleted")
+			if r.allocator != nil {
+				r.allocator.Release(req.NamespacedName.String())
+			}
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -70,14 +86,525 @@ func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	if svcImport.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.allocateIP(ctx, svcImport); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.deriveService(ctx, svcImport); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ejectedUntil, err := r.deriveEndpoints(ctx, svcImport)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	result, err := r.updateClustersStatus(ctx, svcImport)
+	if err != nil || ejectedUntil.IsZero() {
+		return result, err
+	}
+
+	// the ejected clusters are added back to the derived Endpoints once the ejection expires
+	return ctrl.Result{RequeueAfter: time.Until(ejectedUntil) + time.Second}, nil
+}
+
+// allocateIP allocates the VIP of ClusterSetIP ServiceImport, the VIP is persisted in spec.ips
+func (r *ServiceImportReconciler) allocateIP(ctx context.Context, svcImport *svcimpv1alpha1.ServiceImport) error {
+	allocator, err := r.getAllocator(ctx)
+	if err != nil {
+		return err
+	}
+
+	if svcImport.Spec.Type != svcimpv1alpha1.ClusterSetIP {
+		return r.releaseIP(ctx, svcImport, allocator)
+	}
+
+	if allocator == nil {
+		return nil
+	}
+
+	reserved, err := r.serviceIPs(ctx, allocator)
+	if err != nil {
+		return err
+	}
+
+	owner := client.ObjectKeyFromObject(svcImport).String()
+	if len(svcImport.Spec.IPs) > 0 {
+		ip := svcImport.Spec.IPs[0]
+		if reserved[ip] {
+			klog.Warningf("VIP %s of ServiceImport %s collides with a Service, re-allocating ...", ip, owner)
+			r.Recorder.Eventf(svcImport, corev1.EventTypeWarning, "VIPCollision", "VIP %s collides with a Service, will be re-allocated", ip)
+		} else if err := allocator.Assign(owner, ip); err != nil {
+			klog.Warningf("VIP %s of ServiceImport %s can't be assigned, re-allocating ...: %s", ip, owner, err)
+			r.Recorder.Eventf(svcImport, corev1.EventTypeWarning, "VIPCollision", "VIP %s can't be assigned, will be re-allocated: %s", ip, err)
+		} else {
+			return nil
+		}
+	}
+
+	ip, err := allocator.Allocate(owner, reserved)
+	if err != nil {
+		klog.Errorf("Failed to allocate VIP for ServiceImport %s, %s", owner, err)
+		r.Recorder.Eventf(svcImport, corev1.EventTypeWarning, "VIPAllocationFailed", "Failed to allocate VIP: %s", err)
+		return err
+	}
+
+	svcImport.Spec.IPs = []string{ip}
+	if err := r.Update(ctx, svcImport); err != nil {
+		allocator.Release(owner)
+		klog.Errorf("Failed to update VIP of ServiceImport %s, %#v", owner, err)
+		return err
+	}
+	r.Recorder.Eventf(svcImport, corev1.EventTypeNormal, "VIPAllocated", "Allocated VIP %s", ip)
+
+	return nil
+}
+
+// releaseIP releases the VIP of ServiceImport which is no longer ClusterSetIP, e.g. it becomes Headless
+func (r *ServiceImportReconciler) releaseIP(ctx context.Context, svcImport *svcimpv1alpha1.ServiceImport, allocator *ipam.Allocator) error {
+	owner := client.ObjectKeyFromObject(svcImport).String()
+	if allocator != nil {
+		allocator.Release(owner)
+	}
+
+	if len(svcImport.Spec.IPs) == 0 {
+		return nil
+	}
+
+	ip := svcImport.Spec.IPs[0]
+	svcImport.Spec.IPs = nil
+	if err := r.Update(ctx, svcImport); err != nil {
+		klog.Errorf("Failed to release VIP of ServiceImport %s, %#v", owner, err)
+		return err
+	}
+	r.Recorder.Eventf(svcImport, corev1.EventTypeNormal, "VIPReleased", "Released VIP %s as the ServiceImport is %s", ip, svcImport.Spec.Type)
+
+	return nil
+}
+
+// getAllocator returns the VIP allocator, it's nil if VIP CIDR is not configured.
+// Once the allocator is (re)created, the VIPs persisted in ServiceImports are assigned back,
+// so that restarting doesn't reshuffle VIPs.
+func (r *ServiceImportReconciler) getAllocator(ctx context.Context) (*ipam.Allocator, error) {
+	mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
+	cidr := mc.ClusterSet.VIPCIDR
+	if cidr == "" {
+		r.allocator = nil
+		return nil, nil
+	}
+
+	if r.allocator != nil && r.allocator.CIDR() == cidr {
+		return r.allocator, nil
+	}
+
+	allocator, err := ipam.NewAllocator(cidr)
+	if err != nil {
+		klog.Errorf("Failed to create VIP allocator, %s", err)
+		return nil, err
+	}
+
+	svcImports := &svcimpv1alpha1.ServiceImportList{}
+	if err := r.List(ctx, svcImports); err != nil {
+		klog.Errorf("Failed to list ServiceImports, %#v", err)
+		return nil, err
+	}
+
+	for _, imp := range svcImports.Items {
+		if imp.Spec.Type != svcimpv1alpha1.ClusterSetIP || len(imp.Spec.IPs) == 0 {
+			continue
+		}
+
+		// Failure means collision, it'll be re-allocated when the ServiceImport is reconciled
+		if err := allocator.Assign(client.ObjectKeyFromObject(&imp).String(), imp.Spec.IPs[0]); err != nil {
+			klog.Warningf("Failed to assign VIP of ServiceImport %s/%s, %s", imp.Namespace, imp.Name, err)
+		}
+	}
+
+	r.allocator = allocator
+
+	return allocator, nil
+}
+
+// serviceIPs returns the ClusterIPs of Services which are in range of VIP CIDR
+func (r *ServiceImportReconciler) serviceIPs(ctx context.Context, allocator *ipam.Allocator) (map[string]bool, error) {
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services); err != nil {
+		klog.Errorf("Failed to list Services, %#v", err)
+		return nil, err
+	}
+
+	result := make(map[string]bool)
+	for _, svc := range services.Items {
+		for _, ip := range svc.Spec.ClusterIPs {
+			if allocator.Contains(ip) {
+				result[ip] = true
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// deriveService creates/updates the ClusterIP Service derived from the ServiceImport, so that
+// the imported service can be consumed by plain kubernetes clients and DNS
+func (r *ServiceImportReconciler) deriveService(ctx context.Context, svcImport *svcimpv1alpha1.ServiceImport) error {
+	svc := newDerivedService(svcImport)
+	if err := ctrl.SetControllerReference(svcImport, svc, r.Scheme); err != nil {
+		return err
+	}
+
+	result, err := util.CreateOrUpdate(ctx, r.Client, svc)
+	if err != nil {
+		klog.Errorf("Failed to create/update derived Service %s/%s, %#v", svc.Namespace, svc.Name, err)
+		return err
+	}
+
+	if result == controllerutil.OperationResultCreated {
+		r.Recorder.Eventf(svcImport, corev1.EventTypeNormal, "DerivedService", "Created derived Service %s/%s", svc.Namespace, svc.Name)
+	}
+
+	return nil
+}
+
+func newDerivedService(svcImport *svcimpv1alpha1.ServiceImport) *corev1.Service {
+	ports := make([]corev1.ServicePort, 0)
+	for _, p := range svcImport.Spec.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:        p.Name,
+			Protocol:    p.Protocol,
+			AppProtocol: p.AppProtocol,
+			Port:        p.Port,
+		})
+	}
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svcImport.DerivedServiceName(),
+			Namespace: svcImport.Namespace,
+			Labels: map[string]string{
+				commons.ServiceNameLabel: svcImport.Name,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeClusterIP,
+			Ports:                 ports,
+			SessionAffinity:       svcImport.Spec.SessionAffinity,
+			SessionAffinityConfig: svcImport.Spec.SessionAffinityConfig,
+		},
+	}
+}
+
+// deriveEndpoints creates/updates the Endpoints of derived Service, they point to the egress of ingress-pipy
+// for HTTP imports, which sends requests to the gateways of exporting clusters with the exported path and
+// gateway certificate, or the gateways themselves otherwise. The gateways route HTTP requests by the exported
+// path, so HTTP ports have no endpoints if the egress isn't available. As the derived Service has no selector,
+// the EndpointSlices are mirrored from the Endpoints by kubernetes. It returns the earliest time one of the
+// ejected clusters comes back.
+func (r *ServiceImportReconciler) deriveEndpoints(ctx context.Context, svcImport *svcimpv1alpha1.ServiceImport) (time.Time, error) {
+	family, err := r.derivedServiceIPFamily(ctx, svcImport)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	egress, err := r.clusterEgress(ctx, family)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
+	ejected, ejectedUntil := outlier.EjectedClusters(svcImport, mc.ClusterSet.OutlierDetection, time.Now())
+
+	ep, unserved := newDerivedEndpoints(svcImport, family, egress, ejected)
+	if len(unserved) > 0 {
+		klog.Warningf("HTTP ports %v of ServiceImport %s/%s have no endpoints, the cluster egress isn't available", unserved, svcImport.Namespace, svcImport.Name)
+		r.Recorder.Eventf(svcImport, corev1.EventTypeWarning, "EgressUnavailable", "HTTP ports %v have no endpoints, they're only served through the cluster egress of ingress-pipy which is disabled or not ready", unserved)
+	}
+
+	if len(ep.Subsets) == 0 {
+		// Merge patch doesn't clear subsets, just delete the Endpoints if there's no address at all
+		if err := r.Delete(ctx, ep); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("Failed to delete derived Endpoints %s/%s, %#v", ep.Namespace, ep.Name, err)
+			return time.Time{}, err
+		}
+
+		return ejectedUntil, nil
+	}
+
+	if err := ctrl.SetControllerReference(svcImport, ep, r.Scheme); err != nil {
+		return time.Time{}, err
+	}
+
+	if _, err := util.CreateOrUpdate(ctx, r.Client, ep); err != nil {
+		klog.Errorf("Failed to create/update derived Endpoints %s/%s, %#v", ep.Namespace, ep.Name, err)
+		return time.Time{}, err
+	}
+
+	return ejectedUntil, nil
+}
+
+// derivedServiceIPFamily returns the primary IP family of the derived Service, it's assigned by
+// the apiserver according to the IP families of cluster
+func (r *ServiceImportReconciler) derivedServiceIPFamily(ctx context.Context, svcImport *svcimpv1alpha1.ServiceImport) (corev1.IPFamily, error) {
+	svc := &corev1.Service{}
+	key := client.ObjectKey{Namespace: svcImport.Namespace, Name: svcImport.DerivedServiceName()}
+	if err := r.Get(ctx, key, svc); err != nil {
+		klog.Errorf("Failed to get derived Service %s, %#v", key, err)
+		return "", err
+	}
+
+	if len(svc.Spec.IPFamilies) > 0 {
+		return svc.Spec.IPFamilies[0], nil
+	}
+
+	return corev1.IPv4Protocol, nil
+}
+
+// clusterEgress returns the ready Pods of egress of ingress-pipy with the port it listens on, only addresses
+// of the family are returned. It's nil if the egress isn't available
+func (r *ServiceImportReconciler) clusterEgress(ctx context.Context, family corev1.IPFamily) (*corev1.EndpointSubset, error) {
+	mc := r.ControlPlaneConfigStore.MeshConfig.GetConfig()
+	if !mc.IsClusterEgressEnabled() {
+		return nil, nil
+	}
+
+	ep := &corev1.Endpoints{}
+	key := client.ObjectKey{Namespace: config.GetErieCanalNamespace(), Name: commons.ClusterEgressServiceName}
+	if err := r.Get(ctx, key, ep); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	addresses := make([]corev1.EndpointAddress, 0)
+	seen := make(map[string]bool)
+	for _, subset := range ep.Subsets {
+		for _, addr := range subset.Addresses {
+			ip := net.ParseIP(addr.IP)
+			if ip == nil || seen[addr.IP] || (ip.To4() != nil) != (family == corev1.IPv4Protocol) {
+				continue
+			}
+
+			seen[addr.IP] = true
+			addresses = append(addresses, corev1.EndpointAddress{IP: addr.IP})
+		}
+	}
+
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	return &corev1.EndpointSubset{
+		Addresses: addresses,
+		Ports:     []corev1.EndpointPort{{Port: mc.ClusterSet.Egress.Listen}},
+	}, nil
+}
+
+// newDerivedEndpoints builds the Endpoints of derived Service with the gateway IPs of the family,
+// the Endpoints of a dual-stack Service only have addresses of its primary family,
+// gateways of unhealthy and ejected clusters are left out. HTTP ports are served by the egress,
+// the ports left without endpoints as the egress isn't available are returned as well
+func newDerivedEndpoints(svcImport *svcimpv1alpha1.ServiceImport, family corev1.IPFamily, egress *corev1.EndpointSubset, ejected mapset.Set[string]) (*corev1.Endpoints, []int32) {
+	subsets := make([]corev1.EndpointSubset, 0)
+	unserved := make([]int32, 0)
+	for _, p := range svcImport.Spec.Ports {
+		// endpoints of a service port are grouped by gateway port
+		addresses := make(map[int32][]corev1.EndpointAddress)
+		targetPorts := make([]int32, 0)
+		// endpoints of Pods of a headless service share the same gateway address
+		seen := make(map[string]bool)
+		for _, ep := range p.Endpoints {
+			if ejected.Contains(ep.ClusterKey) {
+				continue
+			}
+
+			ip := ep.Target.IPOfFamily(family)
+			if net.ParseIP(ip) == nil {
+				klog.Warningf("Cluster %s has no valid %s gateway IP, ignore it", ep.ClusterKey, family)
+				continue
+			}
+
+			addr := net.JoinHostPort(ip, strconv.Itoa(int(ep.Target.Port)))
+			if seen[addr] {
+				continue
+			}
+			seen[addr] = true
+
+			if _, ok := addresses[ep.Target.Port]; !ok {
+				targetPorts = append(targetPorts, ep.Target.Port)
+			}
+			addresses[ep.Target.Port] = append(addresses[ep.Target.Port], corev1.EndpointAddress{IP: ip})
+		}
+
+		if len(targetPorts) > 0 && isHTTPPort(p) {
+			if egress == nil {
+				unserved = append(unserved, p.Port)
+				continue
+			}
+
+			subsets = append(subsets, corev1.EndpointSubset{
+				Addresses: egress.Addresses,
+				Ports: []corev1.EndpointPort{
+					{
+						Name:        p.Name,
+						Port:        egress.Ports[0].Port,
+						Protocol:    p.Protocol,
+						AppProtocol: p.AppProtocol,
+					},
+				},
+			})
+			continue
+		}
+
+		for _, port := range targetPorts {
+			subsets = append(subsets, corev1.EndpointSubset{
+				Addresses: addresses[port],
+				Ports: []corev1.EndpointPort{
+					{
+						Name:        p.Name,
+						Port:        port,
+						Protocol:    p.Protocol,
+						AppProtocol: p.AppProtocol,
+					},
+				},
+			})
+		}
+	}
+
+	return &corev1.Endpoints{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Endpoints",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svcImport.DerivedServiceName(),
+			Namespace: svcImport.Namespace,
+			Labels: map[string]string{
+				commons.ServiceNameLabel: svcImport.Name,
+			},
+		},
+		Subsets: subsets,
+	}, unserved
+}
+
+func (r *ServiceImportReconciler) updateClustersStatus(ctx context.Context, svcImport *svcimpv1alpha1.ServiceImport) (ctrl.Result, error) {
+	clusters := clusterStatuses(svcImport)
+	if equality.Semantic.DeepEqual(svcImport.Status.Clusters, clusters) {
+		return ctrl.Result{}, nil
+	}
+
+	svcImport.Status.Clusters = clusters
+	if err := r.Status().Update(ctx, svcImport); err != nil {
+		klog.Errorf("Failed to update status of ServiceImport %s/%s, %#v", svcImport.Namespace, svcImport.Name, err)
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// clusterStatuses groups the gateway URLs of endpoints by exporting cluster, sorted by cluster key,
+// the health and ejections by outlier detection are kept as is
+func clusterStatuses(svcImport *svcimpv1alpha1.ServiceImport) []svcimpv1alpha1.ClusterStatus {
+	previous := make(map[string]svcimpv1alpha1.ClusterStatus)
+	for _, cs := range svcImport.Status.Clusters {
+		previous[cs.Cluster] = cs
+	}
+
+	addresses := make(map[string]mapset.Set[string])
+	for _, p := range svcImport.Spec.Ports {
+		for _, ep := range p.Endpoints {
+			if _, ok := addresses[ep.ClusterKey]; !ok {
+				addresses[ep.ClusterKey] = mapset.NewSet[string]()
+			}
+			addresses[ep.ClusterKey].Add(gatewayURL(ep.Target))
+		}
+	}
+
+	keys := make([]string, 0, len(addresses))
+	for key := range addresses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	clusters := make([]svcimpv1alpha1.ClusterStatus, 0)
+	for _, key := range keys {
+		addrs := addresses[key].ToSlice()
+		sort.Strings(addrs)
+		clusters = append(clusters, svcimpv1alpha1.ClusterStatus{
+			Cluster:   key,
+			Addresses: addrs,
+			Unhealthy: previous[key].Unhealthy,
+			Ejection:  previous[key].Ejection,
+		})
+	}
+
+	return clusters
+}
+
+func gatewayURL(target svcimpv1alpha1.Target) string {
+	host := target.Host
+	if host == "" {
+		host = target.IP
+	}
+
+	scheme := "http"
+	if target.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(target.Port))), target.Path)
+}
+
+// isHTTPPort returns true if the port is exported by HTTP rules in all clusters, requests of L4 exports
+// are proxied by the gateways as is
+func isHTTPPort(port svcimpv1alpha1.ServicePort) bool {
+	for _, ep := range port.Endpoints {
+		if ep.Target.Path == "" {
+			return false
+		}
+	}
+
+	return len(port.Endpoints) > 0
+}
+
+// egressToServiceImports maps the Endpoints of egress of ingress-pipy to all ServiceImports, as the derived
+// Endpoints of HTTP imports point to it
+func (r *ServiceImportReconciler) egressToServiceImports(obj client.Object) []reconcile.Request {
+	if obj.GetNamespace() != config.GetErieCanalNamespace() || obj.GetName() != commons.ClusterEgressServiceName {
+		return nil
+	}
+
+	imports := &svcimpv1alpha1.ServiceImportList{}
+	if err := r.List(context.TODO(), imports); err != nil {
+		klog.Errorf("Failed to list ServiceImports, %s", err)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, imp := range imports.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&imp)})
+	}
+
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceImportReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&svcimpv1alpha1.ServiceImport{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Endpoints{}).
+		Watches(
+			&source.Kind{Type: &corev1.Endpoints{}},
+			handler.EnqueueRequestsFromMapFunc(r.egressToServiceImports),
+		).
 		Complete(r)
 }
@@ -391,13 +391,17 @@ func (r *ServiceReconciler) podIPs(ctx context.Context, pods []corev1.Pod, svc *
 	return ips, nil
 }
 
+// filterByIPFamily returns the node IPs of the IP families of Service, a dual-stack Service
+// gets the addresses in order of its IP families
 func filterByIPFamily(ips []string, svc *corev1.Service) ([]string, error) {
 	var ipFamilyPolicy corev1.IPFamilyPolicyType
-	var ipv4Addresses []string
+	addresses := make(map[corev1.IPFamily][]string)
 
 	for _, ip := range ips {
 		if net.IsIPv4String(ip) {
-			ipv4Addresses = append(ipv4Addresses, ip)
+			addresses[corev1.IPv4Protocol] = append(addresses[corev1.IPv4Protocol], ip)
+		} else if net.IsIPv6String(ip) {
+			addresses[corev1.IPv6Protocol] = append(addresses[corev1.IPv6Protocol], ip)
 		}
 	}
 
@@ -405,14 +409,26 @@ func filterByIPFamily(ips []string, svc *corev1.Service) ([]string, error) {
 		ipFamilyPolicy = *svc.Spec.IPFamilyPolicy
 	}
 
+	families := svc.Spec.IPFamilies
+	if len(families) == 0 {
+		families = []corev1.IPFamily{corev1.IPv4Protocol}
+	}
+
 	switch ipFamilyPolicy {
-	case corev1.IPFamilyPolicySingleStack:
-		if svc.Spec.IPFamilies[0] == corev1.IPv4Protocol {
-			return ipv4Addresses, nil
+	case "", corev1.IPFamilyPolicySingleStack:
+		return addresses[families[0]], nil
+	case corev1.IPFamilyPolicyPreferDualStack, corev1.IPFamilyPolicyRequireDualStack:
+		var result []string
+		for _, family := range families {
+			if len(addresses[family]) == 0 && ipFamilyPolicy == corev1.IPFamilyPolicyRequireDualStack {
+				return nil, fmt.Errorf("no %s node address for RequireDualStack Service %s/%s", family, svc.Namespace, svc.Name)
+			}
+			result = append(result, addresses[family]...)
 		}
+		return result, nil
 	}
 
-	return nil, fmt.Errorf("unhandled ipFamilyPolicy")
+	return nil, fmt.Errorf("unhandled ipFamilyPolicy %q", ipFamilyPolicy)
 }
 
 func (r *ServiceReconciler) addFinalizer(ctx context.Context, svc *corev1.Service) error {
@@ -25,13 +25,15 @@ type Controllers interface {
 }
 
 type LocalControllers struct {
-	Service        *controller.ServiceController
-	Endpoints      *controller.EndpointsController
-	Ingressv1      *controller.Ingressv1Controller
-	IngressClassv1 *controller.IngressClassv1Controller
-	ServiceImport  *controller.ServiceImportController
-	Secret         *controller.SecretController
-	GatewayApi     *GatewayApiControllers
+	Service             *controller.ServiceController
+	Endpoints           *controller.EndpointsController
+	Ingressv1           *controller.Ingressv1Controller
+	IngressClassv1      *controller.IngressClassv1Controller
+	ServiceImport       *controller.ServiceImportController
+	ServiceExport       *controller.ServiceExportController
+	GlobalTrafficPolicy *controller.GlobalTrafficPolicyController
+	Secret              *controller.SecretController
+	GatewayApi          *GatewayApiControllers
 }
 
 var _ Controllers = &LocalControllers{}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"fmt"
+	mapset "github.com/deckarep/golang-set/v2"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	routepkg "github.com/flomesh-io/ErieCanal/pkg/route"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// buildEgress routes the requests of imported services by Host to the targets of service routes, so that
+// derived Services and sidecars reach the gateways of other clusters without knowing the exported path and
+// holding the gateway certificate
+func (c *LocalCache) buildEgress(serviceRoutes routepkg.ServiceRoute) *routepkg.EgressSpec {
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	if !mc.IsClusterEgressEnabled() {
+		return nil
+	}
+
+	routes := make(map[string]routepkg.ServiceRouteEntry)
+	for _, r := range serviceRoutes.Routes {
+		routes[servicePortName(r)] = r
+	}
+
+	egress := &routepkg.EgressSpec{
+		Listen:      mc.ClusterSet.Egress.Listen,
+		Certificate: c.gatewayCertificate(),
+		Hosts:       map[string]string{},
+		Services:    map[string][]routepkg.EgressTarget{},
+		Imports:     map[string]string{},
+	}
+
+	for svcName, svcImp := range c.serviceImportMap {
+		svcImpInfo, ok := svcImp.(*serviceImportInfo)
+		if !ok {
+			continue
+		}
+
+		name := svcImpInfo.svcName
+		hosts := append(
+			serviceHosts(svcimpv1alpha1.DerivedServiceName(name.Name), name.Namespace),
+			fmt.Sprintf("%s.%s.svc.%s", name.Name, name.Namespace, commons.ClusterSetDomain),
+		)
+		if svcImpInfo.Address() != "" {
+			hosts = append(hosts, svcImpInfo.Address())
+		}
+		c.addEgressRoute(egress, routes[svcName.String()], name, hosts, svcImpInfo.Port())
+
+		// Pods of headless service are routed by their own hosts, Pods of ejected clusters have no route
+		for _, r := range podRoutes(svcImpInfo, c.multiClusterEndpointsMap[svcName], mapset.NewSet[string]()) {
+			c.addEgressRoute(egress, routes[servicePortName(r)], name, []string{r.Host}, svcImpInfo.Port())
+		}
+	}
+
+	return egress
+}
+
+func (c *LocalCache) addEgressRoute(egress *routepkg.EgressSpec, route routepkg.ServiceRouteEntry, svcImp types.NamespacedName, hosts []string, port int) {
+	if len(route.Targets) == 0 {
+		return
+	}
+
+	service := servicePortName(route)
+	targets := make([]routepkg.EgressTarget, 0)
+	for _, t := range route.Targets {
+		// the address of imported target is the gateway followed by the exported path
+		addr, path, _ := strings.Cut(t.Address, "/")
+		if path != "" {
+			path = "/" + path
+		}
+		target := routepkg.EgressTarget{Address: addr, Path: path, TLS: t.TLS}
+		if w, err := strconv.Atoi(t.Tags[weightTag]); err == nil {
+			target.Weight = w
+		}
+		targets = append(targets, target)
+	}
+	egress.Services[service] = targets
+	egress.Imports[service] = svcImp.String()
+
+	for _, h := range hosts {
+		// the port is omitted from Host header if it's the default one
+		if port == 80 {
+			egress.Hosts[h] = service
+		}
+		egress.Hosts[net.JoinHostPort(h, strconv.Itoa(port))] = service
+	}
+}
+
+// serviceHosts returns the names which a Service is resolved by in cluster
+func serviceHosts(name, namespace string) []string {
+	return []string{
+		name,
+		fmt.Sprintf("%s.%s", name, namespace),
+		fmt.Sprintf("%s.%s.svc", name, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace),
+	}
+}
+
+// clusterEgress returns the address of egress of ingress-pipy, it's empty if the egress isn't available
+func (c *LocalCache) clusterEgress() string {
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	if !mc.IsClusterEgressEnabled() {
+		return ""
+	}
+
+	svc, err := c.controllers.Service.Lister.
+		Services(config.GetErieCanalNamespace()).
+		Get(commons.ClusterEgressServiceName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get cluster egress Service: %s", err)
+		}
+		return ""
+	}
+
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return ""
+	}
+
+	return net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(int(mc.ClusterSet.Egress.Listen)))
+}
+
+// viaEgress replaces the targets which only accept mTLS with the egress of ingress-pipy, the gateway
+// certificate is never handed to sidecars. The targets are dropped if the egress isn't available
+func viaEgress(route routepkg.ServiceRouteEntry, egress string) routepkg.ServiceRouteEntry {
+	targets := make([]routepkg.Target, 0)
+	weight, weighted := 0, false
+	for _, t := range route.Targets {
+		if t.TLS == nil {
+			targets = append(targets, t)
+			continue
+		}
+
+		if w, err := strconv.Atoi(t.Tags[weightTag]); err == nil {
+			weight += w
+			weighted = true
+		}
+	}
+
+	if egress != "" && len(targets) < len(route.Targets) {
+		t := routepkg.Target{Address: egress, Tags: map[string]string{}}
+		if weighted {
+			t.Tags[weightTag] = strconv.Itoa(weight)
+		}
+		targets = append(targets, t)
+	}
+
+	route.Targets = targets
+	return route
+}
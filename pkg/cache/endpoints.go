@@ -17,13 +17,13 @@
 package cache
 
 import (
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
 	"github.com/flomesh-io/ErieCanal/pkg/cache/controller"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 	utilcache "k8s.io/kubernetes/pkg/proxy/util"
-	utilnet "k8s.io/utils/net"
 	"net"
 	"reflect"
 	"strconv"
@@ -35,6 +35,8 @@ type BaseEndpointInfo struct {
 	Nodename string
 	Hostname string
 	Cluster  string
+	// TLS is set if the endpoint is the gateway of other cluster which only accepts mTLS traffic
+	TLS *svcimpv1alpha1.TargetTLS
 }
 
 var _ Endpoint = &BaseEndpointInfo{}
@@ -63,6 +65,10 @@ func (info *BaseEndpointInfo) ClusterInfo() string {
 	return info.Cluster
 }
 
+func (info *BaseEndpointInfo) GatewayTLS() *svcimpv1alpha1.TargetTLS {
+	return info.TLS
+}
+
 func (info *BaseEndpointInfo) Equal(other Endpoint) bool {
 	return info.String() == other.String()
 }
@@ -177,11 +183,6 @@ func (ect *EndpointChangeTracker) endpointsToEndpointsMap(endpoints *corev1.Endp
 					continue
 				}
 
-				// ONLY supports IPv4
-				if !utilnet.IsIPv4String(addr.IP) {
-					continue
-				}
-
 				klog.V(5).Infof("Address = %#v", addr)
 
 				baseEndpointInfo := newBaseEndpointInfo(addr.IP, int(port.Port), nodename(addr), addr.Hostname)
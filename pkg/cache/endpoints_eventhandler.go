@@ -39,7 +39,7 @@ func (c *LocalCache) OnEndpointsDelete(endpoints *corev1.Endpoints) {
 func (c *LocalCache) OnEndpointsSynced() {
 	c.mu.Lock()
 	c.endpointsSynced = true
-	c.setInitialized(c.servicesSynced && c.serviceImportSynced && c.ingressesSynced && c.ingressClassesSynced)
+	c.setInitialized(c.servicesSynced && c.serviceImportSynced && c.ingressesSynced && c.ingressClassesSynced && c.gtpSynced)
 	c.mu.Unlock()
 
 	c.syncRoutes()
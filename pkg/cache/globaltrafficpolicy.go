@@ -0,0 +1,250 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	mapset "github.com/deckarep/golang-set/v2"
+	gtpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/globaltrafficpolicy/v1alpha1"
+	routepkg "github.com/flomesh-io/ErieCanal/pkg/route"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	clusterTag = "Cluster"
+	weightTag  = "Weight"
+)
+
+// ResolvedTarget is a cluster which traffic is routed to
+type ResolvedTarget struct {
+	ClusterKey string
+	Weight     *int
+}
+
+// ResolveTargets returns the clusters which traffic is routed to according to the policy, available are the
+// clusters which have endpoints of the service, including the local cluster. If there's no policy for the
+// service, it works as Locality without any target.
+//   - Locality: the first available target cluster in order, if no target is specified, the nearest locality
+//     tier which has enough available clusters, the tiers are local cluster, clusters in the same group, zone,
+//     region and all the other available clusters in order
+//   - FailOver: local cluster if it's available, otherwise the first available target cluster in order, if no
+//     target is specified, all the other available clusters are used
+//   - ActiveActive: local cluster and the available target clusters, weighted by the weight of the target, if
+//     no target is specified, all available clusters are used evenly. The local cluster which isn't one of the
+//     targets and the targets without weight have the DefaultWeight
+func ResolveTargets(gtp *gtpv1alpha1.GlobalTrafficPolicy, localKey string, available mapset.Set[string]) []ResolvedTarget {
+	if gtp == nil {
+		return localityTargets(localKey, available, nil)
+	}
+
+	switch gtp.Spec.LbType {
+	case gtpv1alpha1.LocalityLbType:
+		if len(gtp.Spec.Targets) == 0 {
+			return localityTargets(localKey, available, gtp.Spec.LocalityThresholds)
+		}
+
+		return firstAvailableTarget(gtp.Spec.Targets, available)
+	case gtpv1alpha1.FailOverLbType:
+		if available.Contains(localKey) {
+			return []ResolvedTarget{{ClusterKey: localKey}}
+		}
+
+		if len(gtp.Spec.Targets) == 0 {
+			return otherTargets(localKey, available)
+		}
+
+		return firstAvailableTarget(gtp.Spec.Targets, available)
+	case gtpv1alpha1.ActiveActiveLbType:
+		if len(gtp.Spec.Targets) == 0 {
+			result := otherTargets(localKey, available)
+			if available.Contains(localKey) {
+				result = append([]ResolvedTarget{{ClusterKey: localKey}}, result...)
+			}
+			return result
+		}
+
+		result := make([]ResolvedTarget, 0)
+		// local cluster is always active, it has the default weight unless it's one of the targets
+		if available.Contains(localKey) && !hasTarget(gtp.Spec.Targets, localKey) {
+			result = append(result, ResolvedTarget{ClusterKey: localKey, Weight: pointer.Int(gtpv1alpha1.DefaultWeight)})
+		}
+		for _, t := range gtp.Spec.Targets {
+			if available.Contains(t.ClusterKey) {
+				weight := pointer.IntDeref(t.Weight, gtpv1alpha1.DefaultWeight)
+				result = append(result, ResolvedTarget{ClusterKey: t.ClusterKey, Weight: &weight})
+			}
+		}
+
+		return result
+	default:
+		klog.Warningf("Unknown LbType %q of GlobalTrafficPolicy %s/%s, ignore it", gtp.Spec.LbType, gtp.Namespace, gtp.Name)
+		return localityTargets(localKey, available, nil)
+	}
+}
+
+// firstAvailableTarget returns the first target cluster which is available, it's empty if none of them is
+func firstAvailableTarget(targets []gtpv1alpha1.TrafficTarget, available mapset.Set[string]) []ResolvedTarget {
+	for _, t := range targets {
+		if available.Contains(t.ClusterKey) {
+			return []ResolvedTarget{{ClusterKey: t.ClusterKey}}
+		}
+	}
+
+	return []ResolvedTarget{}
+}
+
+// localityTargets returns the available clusters of the nearest locality tier, a tier spills over to the next
+// one if it has fewer available clusters than the threshold
+func localityTargets(localKey string, available mapset.Set[string], thresholds *gtpv1alpha1.LocalityThresholds) []ResolvedTarget {
+	if available.Contains(localKey) {
+		return []ResolvedTarget{{ClusterKey: localKey}}
+	}
+
+	if thresholds == nil {
+		thresholds = &gtpv1alpha1.LocalityThresholds{}
+	}
+
+	// the tiers of region/zone/group, from the nearest to the farthest
+	tiers := []struct {
+		segments  int
+		threshold *int
+	}{
+		{segments: 3, threshold: thresholds.Group},
+		{segments: 2, threshold: thresholds.Zone},
+		{segments: 1, threshold: thresholds.Region},
+	}
+
+	for _, tier := range tiers {
+		locality := localityOf(localKey, tier.segments)
+		nearby := mapset.NewSet[string]()
+		for _, key := range available.ToSlice() {
+			if localityOf(key, tier.segments) == locality {
+				nearby.Add(key)
+			}
+		}
+
+		threshold := 1
+		if tier.threshold != nil && *tier.threshold > 1 {
+			threshold = *tier.threshold
+		}
+
+		if nearby.Cardinality() >= threshold {
+			return otherTargets(localKey, nearby)
+		}
+	}
+
+	return otherTargets(localKey, available)
+}
+
+// localityOf returns the first n segments of cluster key, which is in format of region/zone/group/cluster
+func localityOf(clusterKey string, n int) string {
+	segments := strings.SplitN(clusterKey, "/", n+1)
+	if len(segments) > n {
+		segments = segments[:n]
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// otherTargets returns all available clusters except local cluster, sorted by cluster key
+func otherTargets(localKey string, available mapset.Set[string]) []ResolvedTarget {
+	keys := make([]string, 0)
+	for _, key := range available.ToSlice() {
+		if key != localKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := make([]ResolvedTarget, 0)
+	for _, key := range keys {
+		result = append(result, ResolvedTarget{ClusterKey: key})
+	}
+
+	return result
+}
+
+func hasTarget(targets []gtpv1alpha1.TrafficTarget, clusterKey string) bool {
+	for _, t := range targets {
+		if t.ClusterKey == clusterKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// globalTrafficPolicy returns the GlobalTrafficPolicy with the same namespace/name of the service,
+// nil if there's no such policy.
+func (c *LocalCache) globalTrafficPolicy(svcName ServicePortName) *gtpv1alpha1.GlobalTrafficPolicy {
+	gtp, err := c.controllers.GlobalTrafficPolicy.Lister.
+		GlobalTrafficPolicies(svcName.Namespace).
+		Get(svcName.Name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get GlobalTrafficPolicy %s: %s", svcName.NamespacedName, err)
+		}
+		return nil
+	}
+
+	return gtp
+}
+
+// applyGlobalTrafficPolicy selects targets from local and imported targets of a service according to the policy
+func (c *LocalCache) applyGlobalTrafficPolicy(gtp *gtpv1alpha1.GlobalTrafficPolicy, local, imported []routepkg.Target) []routepkg.Target {
+	localKey := c.connectorConfig.Key()
+
+	targetsByCluster := make(map[string][]routepkg.Target)
+	available := mapset.NewSet[string]()
+	if len(local) > 0 {
+		targetsByCluster[localKey] = local
+		available.Add(localKey)
+	}
+	for _, target := range imported {
+		key := target.Tags[clusterTag]
+		targetsByCluster[key] = append(targetsByCluster[key], target)
+		available.Add(key)
+	}
+
+	result := make([]routepkg.Target, 0)
+	for _, rt := range ResolveTargets(gtp, localKey, available) {
+		for _, target := range targetsByCluster[rt.ClusterKey] {
+			result = append(result, weightedTarget(target, rt.Weight))
+		}
+	}
+
+	return result
+}
+
+func weightedTarget(target routepkg.Target, weight *int) routepkg.Target {
+	if weight == nil {
+		return target
+	}
+
+	tags := make(map[string]string, len(target.Tags)+1)
+	for k, v := range target.Tags {
+		tags[k] = v
+	}
+	tags[weightTag] = strconv.Itoa(*weight)
+	target.Tags = tags
+
+	return target
+}
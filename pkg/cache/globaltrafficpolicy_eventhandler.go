@@ -0,0 +1,53 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"github.com/flomesh-io/ErieCanal/apis/globaltrafficpolicy/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/klog/v2"
+)
+
+func (c *LocalCache) OnGlobalTrafficPolicyAdd(gtp *v1alpha1.GlobalTrafficPolicy) {
+	c.OnGlobalTrafficPolicyUpdate(nil, gtp)
+}
+
+func (c *LocalCache) OnGlobalTrafficPolicyUpdate(oldGtp, gtp *v1alpha1.GlobalTrafficPolicy) {
+	if oldGtp != nil && gtp != nil && equality.Semantic.DeepEqual(oldGtp.Spec, gtp.Spec) {
+		return
+	}
+
+	// Policies are looked up from the lister while building service routes,
+	// there's no change tracker, just trigger a sync.
+	if c.isInitialized() {
+		klog.V(5).Infof("Detects GlobalTrafficPolicy change, syncing...")
+		c.Sync()
+	}
+}
+
+func (c *LocalCache) OnGlobalTrafficPolicyDelete(gtp *v1alpha1.GlobalTrafficPolicy) {
+	c.OnGlobalTrafficPolicyUpdate(gtp, nil)
+}
+
+func (c *LocalCache) OnGlobalTrafficPolicySynced() {
+	c.mu.Lock()
+	c.gtpSynced = true
+	c.setInitialized(c.servicesSynced && c.endpointsSynced && c.serviceImportSynced && c.ingressesSynced && c.ingressClassesSynced)
+	c.mu.Unlock()
+
+	c.syncRoutes()
+}
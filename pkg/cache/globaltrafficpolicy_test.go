@@ -0,0 +1,211 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"fmt"
+	mapset "github.com/deckarep/golang-set/v2"
+	gtpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/globaltrafficpolicy/v1alpha1"
+	"k8s.io/utils/pointer"
+	"reflect"
+	"testing"
+)
+
+const (
+	localCluster = "r1/z1/g1/local"
+	sameGroup    = "r1/z1/g1/c1"
+	sameZone     = "r1/z1/g2/c2"
+	sameRegion   = "r1/z2/g1/c3"
+	otherRegion  = "r2/z1/g1/c4"
+)
+
+func newGlobalTrafficPolicy(lbType gtpv1alpha1.LoadBalancerType, targets ...gtpv1alpha1.TrafficTarget) *gtpv1alpha1.GlobalTrafficPolicy {
+	return &gtpv1alpha1.GlobalTrafficPolicy{
+		Spec: gtpv1alpha1.GlobalTrafficPolicySpec{
+			LbType:  lbType,
+			Targets: targets,
+		},
+	}
+}
+
+func withThresholds(gtp *gtpv1alpha1.GlobalTrafficPolicy, group, zone, region int) *gtpv1alpha1.GlobalTrafficPolicy {
+	gtp.Spec.LocalityThresholds = &gtpv1alpha1.LocalityThresholds{
+		Group:  pointer.Int(group),
+		Zone:   pointer.Int(zone),
+		Region: pointer.Int(region),
+	}
+
+	return gtp
+}
+
+// targetsOf formats the resolved targets as cluster keys, followed by the weight if it's weighted
+func targetsOf(resolved []ResolvedTarget) []string {
+	result := make([]string, 0)
+	for _, rt := range resolved {
+		if rt.Weight == nil {
+			result = append(result, rt.ClusterKey)
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s=%d", rt.ClusterKey, *rt.Weight))
+	}
+
+	return result
+}
+
+func TestResolveTargets(t *testing.T) {
+	all := []string{localCluster, sameGroup, sameZone, sameRegion, otherRegion}
+	remote := []string{sameGroup, sameZone, sameRegion, otherRegion}
+
+	testCases := []struct {
+		name      string
+		gtp       *gtpv1alpha1.GlobalTrafficPolicy
+		available []string
+		expected  []string
+	}{
+		{
+			name:      "no policy prefers local cluster",
+			available: all,
+			expected:  []string{localCluster},
+		},
+		{
+			name:      "no policy spills over to the same group",
+			available: remote,
+			expected:  []string{sameGroup},
+		},
+		{
+			name:      "no policy spills over to the same zone",
+			available: []string{sameZone, sameRegion, otherRegion},
+			expected:  []string{sameZone},
+		},
+		{
+			name:      "no policy spills over to the same region",
+			available: []string{sameRegion, otherRegion},
+			expected:  []string{sameRegion},
+		},
+		{
+			name:      "no policy spills over to all other clusters",
+			available: []string{otherRegion},
+			expected:  []string{otherRegion},
+		},
+		{
+			name:      "no available cluster",
+			available: []string{},
+			expected:  []string{},
+		},
+		{
+			name:      "Locality prefers local cluster regardless of thresholds",
+			gtp:       withThresholds(newGlobalTrafficPolicy(gtpv1alpha1.LocalityLbType), 3, 3, 3),
+			available: all,
+			expected:  []string{localCluster},
+		},
+		{
+			name:      "Locality group tier below threshold spills over to zone tier",
+			gtp:       withThresholds(newGlobalTrafficPolicy(gtpv1alpha1.LocalityLbType), 2, 1, 1),
+			available: remote,
+			expected:  []string{sameGroup, sameZone},
+		},
+		{
+			name:      "Locality zone tier below threshold spills over to region tier",
+			gtp:       withThresholds(newGlobalTrafficPolicy(gtpv1alpha1.LocalityLbType), 2, 3, 1),
+			available: remote,
+			expected:  []string{sameGroup, sameZone, sameRegion},
+		},
+		{
+			name:      "Locality all tiers below thresholds spill over to all other clusters",
+			gtp:       withThresholds(newGlobalTrafficPolicy(gtpv1alpha1.LocalityLbType), 2, 3, 4),
+			available: remote,
+			expected:  []string{sameGroup, sameZone, sameRegion, otherRegion},
+		},
+		{
+			name:      "Locality threshold below 1 is treated as 1",
+			gtp:       withThresholds(newGlobalTrafficPolicy(gtpv1alpha1.LocalityLbType), 0, 0, 0),
+			available: remote,
+			expected:  []string{sameGroup},
+		},
+		{
+			name: "Locality with targets uses the first available target",
+			gtp: newGlobalTrafficPolicy(gtpv1alpha1.LocalityLbType,
+				gtpv1alpha1.TrafficTarget{ClusterKey: "r3/z1/g1/gone"},
+				gtpv1alpha1.TrafficTarget{ClusterKey: otherRegion},
+				gtpv1alpha1.TrafficTarget{ClusterKey: sameGroup},
+			),
+			available: all,
+			expected:  []string{otherRegion},
+		},
+		{
+			name:      "FailOver prefers local cluster",
+			gtp:       newGlobalTrafficPolicy(gtpv1alpha1.FailOverLbType, gtpv1alpha1.TrafficTarget{ClusterKey: otherRegion}),
+			available: all,
+			expected:  []string{localCluster},
+		},
+		{
+			name: "FailOver uses the first available target",
+			gtp: newGlobalTrafficPolicy(gtpv1alpha1.FailOverLbType,
+				gtpv1alpha1.TrafficTarget{ClusterKey: otherRegion},
+				gtpv1alpha1.TrafficTarget{ClusterKey: sameGroup},
+			),
+			available: remote,
+			expected:  []string{otherRegion},
+		},
+		{
+			name:      "FailOver without targets uses all other clusters",
+			gtp:       newGlobalTrafficPolicy(gtpv1alpha1.FailOverLbType),
+			available: remote,
+			expected:  []string{sameGroup, sameZone, sameRegion, otherRegion},
+		},
+		{
+			name:      "ActiveActive without targets uses all clusters evenly",
+			gtp:       newGlobalTrafficPolicy(gtpv1alpha1.ActiveActiveLbType),
+			available: []string{localCluster, sameZone, otherRegion},
+			expected:  []string{localCluster, sameZone, otherRegion},
+		},
+		{
+			name: "ActiveActive weights unlisted local cluster and targets without weight by default",
+			gtp: newGlobalTrafficPolicy(gtpv1alpha1.ActiveActiveLbType,
+				gtpv1alpha1.TrafficTarget{ClusterKey: sameZone, Weight: pointer.Int(20)},
+				gtpv1alpha1.TrafficTarget{ClusterKey: otherRegion},
+				gtpv1alpha1.TrafficTarget{ClusterKey: sameRegion, Weight: pointer.Int(30)},
+			),
+			available: []string{localCluster, sameZone, otherRegion},
+			expected:  []string{localCluster + "=100", sameZone + "=20", otherRegion + "=100"},
+		},
+		{
+			name: "ActiveActive weights listed local cluster by the target",
+			gtp: newGlobalTrafficPolicy(gtpv1alpha1.ActiveActiveLbType,
+				gtpv1alpha1.TrafficTarget{ClusterKey: sameZone, Weight: pointer.Int(20)},
+				gtpv1alpha1.TrafficTarget{ClusterKey: localCluster, Weight: pointer.Int(10)},
+			),
+			available: all,
+			expected:  []string{sameZone + "=20", localCluster + "=10"},
+		},
+		{
+			name:      "unknown LbType works as Locality",
+			gtp:       newGlobalTrafficPolicy("Unknown"),
+			available: remote,
+			expected:  []string{sameGroup},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			available := mapset.NewSet[string](tc.available...)
+			if actual := targetsOf(ResolveTargets(tc.gtp, localCluster, available)); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
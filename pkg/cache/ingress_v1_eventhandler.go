@@ -36,7 +36,7 @@ func (c *LocalCache) OnIngressv1Delete(ingress *networkingv1.Ingress) {
 func (c *LocalCache) OnIngressv1Synced() {
 	c.mu.Lock()
 	c.ingressesSynced = true
-	c.setInitialized(c.servicesSynced && c.endpointsSynced && c.serviceImportSynced && c.ingressClassesSynced)
+	c.setInitialized(c.servicesSynced && c.endpointsSynced && c.serviceImportSynced && c.ingressClassesSynced && c.gtpSynced)
 	c.mu.Unlock()
 
 	c.syncRoutes()
@@ -41,7 +41,7 @@ func (c *LocalCache) OnIngressClassv1Delete(class *networkingv1.IngressClass) {
 func (c *LocalCache) OnIngressClassv1Synced() {
 	c.mu.Lock()
 	c.ingressClassesSynced = true
-	c.setInitialized(c.ingressesSynced && c.servicesSynced && c.endpointsSynced && c.serviceImportSynced)
+	c.setInitialized(c.ingressesSynced && c.servicesSynced && c.endpointsSynced && c.serviceImportSynced && c.gtpSynced)
 	c.mu.Unlock()
 
 	c.syncRoutes()
@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	mapset "github.com/deckarep/golang-set/v2"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
 	"github.com/flomesh-io/ErieCanal/pkg/cache/controller"
 	"github.com/flomesh-io/ErieCanal/pkg/certificate"
 	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
 	"github.com/flomesh-io/ErieCanal/pkg/config"
 	cachectrl "github.com/flomesh-io/ErieCanal/pkg/controller"
 	"github.com/flomesh-io/ErieCanal/pkg/event"
@@ -33,12 +35,18 @@ import (
 	routepkg "github.com/flomesh-io/ErieCanal/pkg/route"
 	"github.com/flomesh-io/ErieCanal/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/util/async"
+	"net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -70,6 +78,7 @@ type LocalCache struct {
 	ingressesSynced      bool
 	ingressClassesSynced bool
 	serviceImportSynced  bool
+	gtpSynced            bool
 	initialized          int32
 
 	syncRunner *async.BoundedFrequencyRunner
@@ -80,6 +89,9 @@ type LocalCache struct {
 
 	ingressRoutesVersion string
 	serviceRoutesVersion string
+
+	// ejectionTimer resyncs routes once the earliest ejection of imported clusters expires
+	ejectionTimer *time.Timer
 }
 
 func newLocalCache(ctx context.Context, api *kube.K8sAPI, clusterCfg *config.Store, broker *event.Broker, certMgr certificate.Manager, resyncPeriod time.Duration) *LocalCache {
@@ -137,14 +149,26 @@ func newLocalCache(ctx context.Context, api *kube.K8sAPI, clusterCfg *config.Sto
 		resyncPeriod,
 		c,
 	)
+	serviceExportController := cachectrl.NewServiceExportControllerWithEventHandler(
+		ecInformerFactory.Serviceexport().V1alpha1().ServiceExports(),
+		resyncPeriod,
+		c,
+	)
+	gtpController := cachectrl.NewGlobalTrafficPolicyControllerWithEventHandler(
+		ecInformerFactory.Globaltrafficpolicy().V1alpha1().GlobalTrafficPolicies(),
+		resyncPeriod,
+		c,
+	)
 
 	c.controllers = &controller.LocalControllers{
-		Service:        serviceController,
-		Endpoints:      endpointsController,
-		Ingressv1:      ingressV1Controller,
-		IngressClassv1: ingressClassV1Controller,
-		ServiceImport:  serviceImortController,
-		Secret:         secretController,
+		Service:             serviceController,
+		Endpoints:           endpointsController,
+		Ingressv1:           ingressV1Controller,
+		IngressClassv1:      ingressClassV1Controller,
+		ServiceImport:       serviceImortController,
+		ServiceExport:       serviceExportController,
+		GlobalTrafficPolicy: gtpController,
+		Secret:              secretController,
 	}
 
 	c.serviceChanges = NewServiceChangeTracker(enrichServiceInfo, recorder, c.controllers, c.k8sAPI)
@@ -240,7 +264,7 @@ func (c *LocalCache) syncRoutes() {
 		c.refreshIngress()
 	}
 
-	ingressRoutes := c.buildIngressConfig()
+	ingressRoutes := c.buildIngressConfig(serviceRoutes)
 	klog.V(5).Infof("Ingress Routes:\n %#v", ingressRoutes)
 	if c.ingressRoutesVersion != ingressRoutes.Hash {
 		klog.V(5).Infof("Ingress Routes changed, old hash=%q, new hash=%q", c.ingressRoutesVersion, ingressRoutes.Hash)
@@ -280,11 +304,12 @@ func (c *LocalCache) refreshIngress() {
 	c.ingressMap.Update(c.ingressChanges)
 }
 
-func (c *LocalCache) buildIngressConfig() routepkg.IngressData {
+func (c *LocalCache) buildIngressConfig(serviceRoutes routepkg.ServiceRoute) routepkg.IngressData {
 	ingressConfig := routepkg.IngressData{
 		//RouteBase: r,
 		//Hash:      hash,
-		Routes: []routepkg.IngressRouteSpec{},
+		Routes:   []routepkg.IngressRouteSpec{},
+		L4Routes: []routepkg.L4RouteSpec{},
 	}
 
 	for _, route := range c.ingressMap {
@@ -341,13 +366,173 @@ func (c *LocalCache) buildIngressConfig() routepkg.IngressData {
 		if len(ir.Upstream.Endpoints) > 0 {
 			ingressConfig.Routes = append(ingressConfig.Routes, ir)
 		}
+
+		ingressConfig.Routes = append(ingressConfig.Routes, c.podIngressRoutes(route, ir)...)
 	}
 
+	ingressConfig.L4Routes = c.buildL4Routes()
+	ingressConfig.GatewayTLS = c.buildGatewayTLS()
+	ingressConfig.OutlierDetection = c.outlierDetection()
+	ingressConfig.Egress = c.buildEgress(serviceRoutes)
 	ingressConfig.Hash = util.SimpleHash(ingressConfig)
 
 	return ingressConfig
 }
 
+// podIngressRoutes builds a route for each Pod of headless Service, the Pod is routed by host
+// <hostname>.<service>.<namespace>.svc.clusterset.local, so that it can be addressed individually
+// from other clusters
+func (c *LocalCache) podIngressRoutes(route Route, ir routepkg.IngressRouteSpec) []routepkg.IngressRouteSpec {
+	svcName := route.Backend()
+	if route.Host() != "" || !c.isHeadlessService(svcName.NamespacedName) {
+		return nil
+	}
+
+	routes := make([]routepkg.IngressRouteSpec, 0)
+	for _, e := range c.endpointsMap[svcName] {
+		ep, ok := e.(*BaseEndpointInfo)
+		if !ok || ep.HostName() == "" {
+			continue
+		}
+
+		epIP := ep.IP()
+		epPort, err := ep.Port()
+		if epIP == "" || err != nil {
+			continue
+		}
+
+		upstream := *ir.Upstream
+		upstream.Endpoints = []routepkg.UpstreamEndpoint{{IP: epIP, Port: epPort}}
+
+		podRoute := ir
+		podRoute.Host = svcimpv1alpha1.PodHost(ep.HostName(), svcName.Name, svcName.Namespace)
+		podRoute.Service = fmt.Sprintf("%s/%s.%s%s", svcName.Namespace, ep.HostName(), svcName.Name, fmtPortName(svcName.Port))
+		podRoute.Upstream = &upstream
+		routes = append(routes, podRoute)
+	}
+
+	return routes
+}
+
+// buildL4Routes proxies each gateway port allocated to ServiceExports in L4 mode to the endpoints of
+// the exported service port. If a port is allocated to more than one export, the oldest one wins.
+func (c *LocalCache) buildL4Routes() []routepkg.L4RouteSpec {
+	routes := make([]routepkg.L4RouteSpec, 0)
+
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	if !mc.Ingress.L4.Enabled {
+		return routes
+	}
+
+	exports, err := c.controllers.ServiceExport.Lister.
+		ServiceExports(corev1.NamespaceAll).
+		List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list all ServiceExports: %s", err)
+		return routes
+	}
+
+	sort.Slice(exports, func(i, j int) bool {
+		if !exports[i].CreationTimestamp.Equal(&exports[j].CreationTimestamp) {
+			return exports[i].CreationTimestamp.Before(&exports[j].CreationTimestamp)
+		}
+		return client.ObjectKeyFromObject(exports[i]).String() < client.ObjectKeyFromObject(exports[j]).String()
+	})
+
+	ports := make(map[int32]bool)
+	for _, export := range exports {
+		if !export.IsL4() {
+			continue
+		}
+
+		for _, gp := range export.Status.GatewayPorts {
+			if ports[gp.GatewayPort] {
+				klog.Warningf("Gateway port %d of ServiceExport %s/%s has been allocated to another export, ignore it", gp.GatewayPort, export.Namespace, export.Name)
+				continue
+			}
+
+			svcName, ok := c.servicePortNameOf(types.NamespacedName{Namespace: export.Namespace, Name: export.Name}, gp.PortNumber, gp.Protocol)
+			if !ok {
+				continue
+			}
+			ports[gp.GatewayPort] = true
+
+			r := routepkg.L4RouteSpec{
+				Port:     gp.GatewayPort,
+				Protocol: string(gp.Protocol),
+				Service:  svcName.String(),
+				BalancerSpec: routepkg.BalancerSpec{
+					Sticky:   export.Spec.SessionSticky,
+					Balancer: l4Balancer(export.Spec.LoadBalancer),
+					Upstream: &routepkg.UpstreamSpec{
+						Endpoints: []routepkg.UpstreamEndpoint{},
+					},
+				},
+			}
+
+			for _, e := range c.endpointsMap[svcName] {
+				ep, ok := e.(*BaseEndpointInfo)
+				if !ok {
+					klog.ErrorS(nil, "Failed to cast BaseEndpointInfo", "endpoint", e.String())
+					continue
+				}
+
+				epIP := ep.IP()
+				epPort, err := ep.Port()
+				if epIP == "" || err != nil {
+					continue
+				}
+				r.Upstream.Endpoints = append(r.Upstream.Endpoints, routepkg.UpstreamEndpoint{
+					IP:       epIP,
+					Port:     epPort,
+					Protocol: string(gp.Protocol),
+				})
+			}
+
+			routes = append(routes, r)
+		}
+	}
+
+	return routes
+}
+
+// servicePortNameOf finds the name of the service port by port number and protocol
+func (c *LocalCache) servicePortNameOf(name types.NamespacedName, port int32, protocol corev1.Protocol) (ServicePortName, bool) {
+	svc, err := c.controllers.Service.Lister.Services(name.Namespace).Get(name.Name)
+	if err != nil {
+		return ServicePortName{}, false
+	}
+
+	for _, p := range svc.Spec.Ports {
+		if p.Port == port && p.Protocol == protocol {
+			return ServicePortName{NamespacedName: name, Port: p.Name, Protocol: p.Protocol}, true
+		}
+	}
+
+	return ServicePortName{}, false
+}
+
+// l4Balancer converts the LoadBalancer type of ServiceExport to the algorithm of ingress-pipy
+func l4Balancer(lb routepkg.AlgoBalancer) routepkg.AlgoBalancer {
+	switch lb {
+	case "HashingLoadBalancer":
+		return routepkg.HashingLoadBalancer
+	case "LeastWorkLoadBalancer":
+		return routepkg.LeastWorkLoadBalancer
+	default:
+		return routepkg.RoundRobinLoadBalancer
+	}
+}
+
+func (c *LocalCache) isHeadlessService(name types.NamespacedName) bool {
+	svc, err := c.controllers.Service.Lister.Services(name.Namespace).Get(name.Name)
+	if err != nil {
+		return false
+	}
+
+	return svc.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
 func (c *LocalCache) ingressBatches(ingressData routepkg.IngressData, mc *config.MeshConfig) []repo.Batch {
 	batch := repo.Batch{
 		Basepath: mc.GetDefaultIngressPath(),
@@ -389,11 +574,21 @@ func (c *LocalCache) ingressBatches(ingressData routepkg.IngressData, mc *config
 		}
 	}
 
+	// Generate l4 proxies
+	l4 := routepkg.L4Config{L4: map[string]routepkg.L4RouteSpec{}}
+	for _, r := range ingressData.L4Routes {
+		l4.L4[strconv.Itoa(int(r.Port))] = r
+	}
+
 	ingressConfig := routepkg.IngressConfig{
-		TrustedCAs:     getTrustedCAs(trustedCAMap),
-		TLSConfig:      certificates,
-		RouterConfig:   router,
-		BalancerConfig: balancer,
+		TrustedCAs:       getTrustedCAs(trustedCAMap),
+		TLSConfig:        certificates,
+		RouterConfig:     router,
+		BalancerConfig:   balancer,
+		L4Config:         l4,
+		GatewayTLS:       ingressData.GatewayTLS,
+		OutlierDetection: ingressData.OutlierDetection,
+		Egress:           ingressData.Egress,
 	}
 
 	batch.Items = append(batch.Items, ingressBatchItems(ingressConfig)...)
@@ -420,6 +615,9 @@ func (c *LocalCache) buildServiceRoutes() routepkg.ServiceRoute {
 		Routes: []routepkg.ServiceRouteEntry{},
 	}
 
+	now := time.Now()
+	var nextResync time.Time
+
 	svcNames := mapset.NewSet[ServicePortName]()
 	for svcName := range c.serviceMap {
 		svcNames.Add(svcName)
@@ -429,6 +627,8 @@ func (c *LocalCache) buildServiceRoutes() routepkg.ServiceRoute {
 	}
 
 	for _, svcName := range svcNames.ToSlice() {
+		var localRoute *routepkg.ServiceRouteEntry
+
 		svc, exists := c.serviceMap[svcName]
 		if exists {
 			svcInfo, ok := svc.(*serviceInfo)
@@ -451,13 +651,13 @@ func (c *LocalCache) buildServiceRoutes() routepkg.ServiceRoute {
 							}},
 						)
 					}
-					serviceRoutes.Routes = append(serviceRoutes.Routes, sr)
+					localRoute = &sr
 				case corev1.ServiceTypeExternalName:
 					sr.Targets = append(sr.Targets, routepkg.Target{
 						Address: svcInfo.Address(),
 						Tags:    map[string]string{}},
 					)
-					serviceRoutes.Routes = append(serviceRoutes.Routes, sr)
+					localRoute = &sr
 				}
 			} else {
 				klog.ErrorS(nil, "Failed to cast serviceInfo", "svcName", svcName.String())
@@ -474,33 +674,166 @@ func (c *LocalCache) buildServiceRoutes() routepkg.ServiceRoute {
 					Targets:   make([]routepkg.Target, 0),
 					PortName:  svcImpInfo.portName,
 				}
+				if svcImpInfo.Address() != "" {
+					sr.VIP = net.JoinHostPort(svcImpInfo.Address(), strconv.Itoa(svcImpInfo.Port()))
+				}
 
+				ejected, until := c.ejectedClusters(svcImpInfo.svcName, now)
+				if !until.IsZero() && (nextResync.IsZero() || until.Before(nextResync)) {
+					nextResync = until
+				}
+
+				imported := make([]routepkg.Target, 0)
+				addrs := mapset.NewSet[string]()
 				for _, ep := range c.multiClusterEndpointsMap[svcName] {
-					sr.Targets = append(sr.Targets, routepkg.Target{
+					if ejected.Contains(ep.ClusterInfo()) {
+						continue
+					}
+					// Pods of a headless service share the same gateway address
+					if !addrs.Add(ep.String()) {
+						continue
+					}
+					imported = append(imported, routepkg.Target{
 						Address: ep.String(),
 						Tags: map[string]string{
-							"Cluster": ep.ClusterInfo(),
-						}},
-					)
+							clusterTag: ep.ClusterInfo(),
+						},
+						TLS: targetTLS(ep.GatewayTLS()),
+					})
+				}
+
+				// local and imported targets are merged into one entry as per the GlobalTrafficPolicy
+				local := make([]routepkg.Target, 0)
+				if localRoute != nil {
+					local = localRoute.Targets
+					localRoute = nil
 				}
+				sr.Targets = c.applyGlobalTrafficPolicy(c.globalTrafficPolicy(svcName), local, imported)
 
 				serviceRoutes.Routes = append(serviceRoutes.Routes, sr)
+				serviceRoutes.Routes = append(serviceRoutes.Routes, podRoutes(svcImpInfo, c.multiClusterEndpointsMap[svcName], ejected)...)
 			}
 		}
+
+		if localRoute != nil {
+			serviceRoutes.Routes = append(serviceRoutes.Routes, *localRoute)
+		}
 	}
+	serviceRoutes.Egress = c.clusterEgress()
+	c.resyncAt(nextResync)
 	serviceRoutes.Hash = util.SimpleHash(serviceRoutes)
 
 	return serviceRoutes
 }
 
+// buildGatewayTLS returns the mTLS listener for traffic from other clusters, it's enabled once
+// the gateway certificate is issued to this cluster
+func (c *LocalCache) buildGatewayTLS() *routepkg.GatewayTLSSpec {
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	if !mc.ClusterSet.GatewayTLS.Enabled || mc.ClusterSet.GatewayTLS.Listen == 0 {
+		return nil
+	}
+
+	cert := c.gatewayCertificate()
+	if cert == nil {
+		return nil
+	}
+
+	return &routepkg.GatewayTLSSpec{
+		Listen:      mc.ClusterSet.GatewayTLS.Listen,
+		Certificate: *cert,
+	}
+}
+
+func targetTLS(tls *svcimpv1alpha1.TargetTLS) *routepkg.TargetTLS {
+	if tls == nil {
+		return nil
+	}
+
+	return &routepkg.TargetTLS{SNI: tls.SNI, CA: tls.CA}
+}
+
+// gatewayCertificate returns the gateway certificate which is issued to this cluster by control plane,
+// it's nil if gateway TLS isn't enabled in control plane
+func (c *LocalCache) gatewayCertificate() *routepkg.CertificateSpec {
+	secret, err := c.controllers.Secret.Lister.
+		Secrets(config.GetErieCanalNamespace()).
+		Get(commons.GatewayTLSSecretName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get gateway certificate: %s", err)
+		}
+		return nil
+	}
+
+	if len(secret.Data[commons.TLSCertName]) == 0 || len(secret.Data[commons.TLSPrivateKeyName]) == 0 {
+		klog.Warningf("Gateway certificate %s/%s is incomplete, ignore it", secret.Namespace, secret.Name)
+		return nil
+	}
+
+	return &routepkg.CertificateSpec{
+		Cert: string(secret.Data[commons.TLSCertName]),
+		Key:  string(secret.Data[commons.TLSPrivateKeyName]),
+		CA:   string(secret.Data[commons.RootCACertName]),
+	}
+}
+
+// podRoutes builds a route for each Pod of headless ServiceImport, named as <hostname>.<cluster>.<service>,
+// requests are sent to the gateway of exporting cluster with the host of the Pod. Pods of ejected clusters
+// are left out
+func podRoutes(svcImpInfo *serviceImportInfo, endpoints []Endpoint, ejected mapset.Set[string]) []routepkg.ServiceRouteEntry {
+	routes := make([]routepkg.ServiceRouteEntry, 0)
+	for _, ep := range endpoints {
+		if ep.HostName() == "" || ejected.Contains(ep.ClusterInfo()) {
+			continue
+		}
+
+		routes = append(routes, routepkg.ServiceRouteEntry{
+			Name:      fmt.Sprintf("%s.%s.%s", ep.HostName(), svcimpv1alpha1.ClusterName(ep.ClusterInfo()), svcImpInfo.svcName.Name),
+			Namespace: svcImpInfo.svcName.Namespace,
+			Targets: []routepkg.Target{
+				{
+					Address: ep.String(),
+					Tags: map[string]string{
+						clusterTag: ep.ClusterInfo(),
+					},
+					TLS: targetTLS(ep.GatewayTLS()),
+				},
+			},
+			PortName: svcImpInfo.portName,
+			Host:     svcimpv1alpha1.PodHost(ep.HostName(), svcImpInfo.svcName.Name, svcImpInfo.svcName.Namespace),
+		})
+	}
+
+	return routes
+}
+
 func serviceBatches(serviceRoutes routepkg.ServiceRoute, mc *config.MeshConfig) []repo.Batch {
-	registry := repo.ServiceRegistry{Services: repo.ServiceRegistryEntry{}}
+	registry := repo.ServiceRegistry{
+		Services: repo.ServiceRegistryEntry{},
+		Weights:  repo.ServiceRegistryWeights{},
+		VIPs:     repo.ServiceRegistryVIPs{},
+		Hosts:    repo.ServiceRegistryHosts{},
+	}
 
 	for _, route := range serviceRoutes.Routes {
+		route = viaEgress(route, serviceRoutes.Egress)
 		addrs := addresses(route)
 		if len(addrs) > 0 {
 			serviceName := servicePortName(route)
 			registry.Services[serviceName] = append(registry.Services[serviceName], addrs...)
+
+			if weights := weights(route); len(weights) > 0 {
+				registry.Weights[serviceName] = weights
+			}
+
+			if route.VIP != "" {
+				registry.VIPs[route.VIP] = serviceName
+			}
+
+			if route.Host != "" {
+				registry.Hosts[serviceName] = route.Host
+			}
 		}
 	}
 
@@ -549,3 +882,22 @@ func addresses(route routepkg.ServiceRouteEntry) []string {
 
 	return result
 }
+
+func weights(route routepkg.ServiceRouteEntry) map[string]int {
+	result := make(map[string]int)
+	for _, target := range route.Targets {
+		w, ok := target.Tags[weightTag]
+		if !ok {
+			continue
+		}
+
+		weight, err := strconv.Atoi(w)
+		if err != nil {
+			klog.Warningf("Invalid weight %q of target %s, ignore it", w, target.Address)
+			continue
+		}
+		result[target.Address] = weight
+	}
+
+	return result
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/klog/v2"
+)
+
+func (c *LocalCache) OnServiceExportAdd(export *svcexpv1alpha1.ServiceExport) {
+	c.OnServiceExportUpdate(nil, export)
+}
+
+func (c *LocalCache) OnServiceExportUpdate(oldExport, export *svcexpv1alpha1.ServiceExport) {
+	if oldExport != nil && export != nil &&
+		equality.Semantic.DeepEqual(oldExport.Spec, export.Spec) &&
+		equality.Semantic.DeepEqual(oldExport.Status.GatewayPorts, export.Status.GatewayPorts) {
+		return
+	}
+
+	// L4 proxies are built from the lister while building ingress config,
+	// there's no change tracker, just trigger a sync.
+	if c.isInitialized() {
+		klog.V(5).Infof("Detects ServiceExport change, syncing...")
+		c.Sync()
+	}
+}
+
+func (c *LocalCache) OnServiceExportDelete(export *svcexpv1alpha1.ServiceExport) {
+	c.OnServiceExportUpdate(export, nil)
+}
+
+func (c *LocalCache) OnServiceExportSynced() {
+	// ServiceExport informer is synced before Ingress informer, it doesn't change the initialized state
+	if c.isInitialized() {
+		c.Sync()
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"fmt"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/flomesh-io/ErieCanal/pkg/outlier"
+	routepkg "github.com/flomesh-io/ErieCanal/pkg/route"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"time"
+)
+
+// ejectedClusters returns the exporting clusters which are unhealthy or ejected from the ServiceImport
+// by outlier detection, and the earliest time one of the ejections expires
+func (c *LocalCache) ejectedClusters(name types.NamespacedName, now time.Time) (mapset.Set[string], time.Time) {
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+
+	svcImp, err := c.controllers.ServiceImport.Lister.ServiceImports(name.Namespace).Get(name.Name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get ServiceImport %s: %s", name, err)
+		}
+		return mapset.NewSet[string](), time.Time{}
+	}
+
+	return outlier.EjectedClusters(svcImp, mc.ClusterSet.OutlierDetection, now)
+}
+
+// resyncAt schedules a sync at the time, so that ejected clusters are added back to the routes once
+// the ejection expires. It replaces the previous schedule, a zero time cancels it
+func (c *LocalCache) resyncAt(t time.Time) {
+	if c.ejectionTimer != nil {
+		c.ejectionTimer.Stop()
+		c.ejectionTimer = nil
+	}
+
+	if t.IsZero() {
+		return
+	}
+
+	c.ejectionTimer = time.AfterFunc(time.Until(t)+time.Second, c.Sync)
+}
+
+// outlierDetection returns where ingress-pipy reports failures of targets to and the client certificate it
+// reports with, it's nil if outlier detection is disabled
+func (c *LocalCache) outlierDetection() *routepkg.OutlierDetectionSpec {
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	od := mc.ClusterSet.OutlierDetection
+	if !od.Enabled || od.Port == 0 {
+		return nil
+	}
+
+	cert, err := outlier.ReporterCertificate(c.certMgr)
+	if err != nil {
+		klog.Errorf("Failed to issue certificate of outlier reporter: %s", err)
+		return nil
+	}
+
+	return &routepkg.OutlierDetectionSpec{
+		Report:   fmt.Sprintf("https://%s:%d%s", outlier.ServerName(), od.Port, outlier.ReportPath),
+		Interval: int32(od.ReportPeriod().Seconds()),
+		Certificate: &routepkg.CertificateSpec{
+			Cert: string(cert.CrtPEM),
+			Key:  string(cert.KeyPEM),
+			CA:   string(cert.CA),
+		},
+	}
+}
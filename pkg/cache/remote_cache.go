@@ -19,6 +19,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
 	"github.com/flomesh-io/ErieCanal/pkg/cache/controller"
 	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
 	"github.com/flomesh-io/ErieCanal/pkg/config"
@@ -26,6 +27,8 @@ import (
 	"github.com/flomesh-io/ErieCanal/pkg/event"
 	ecinformers "github.com/flomesh-io/ErieCanal/pkg/generated/informers/externalversions"
 	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	metautil "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
@@ -100,11 +103,45 @@ func (c *RemoteCache) setInitialized(value bool) {
 }
 
 func (c *RemoteCache) syncManagedCluster() {
-	// Nothing to do for the time-being
-
 	//c.mu.Lock()
 	//defer c.mu.Unlock()
 	klog.Infof("[%s] Syncing resources of managed clusters ...", c.connectorConfig.Key())
+
+	// exports deleted while no connector was running, e.g. during the handover of leadership,
+	// never trigger delete events, publish all exports periodically so that other clusters prune them
+	if atomic.LoadInt32(&c.initialized) == 0 {
+		return
+	}
+
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	if !mc.IsManaged {
+		return
+	}
+
+	exports, err := c.controllers.ServiceExport.Lister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("[%s] Failed to list ServiceExports: %s", c.connectorConfig.Key(), err)
+		return
+	}
+
+	active := make([]*svcexpv1alpha1.ServiceExport, 0)
+	for _, export := range exports {
+		if !mc.IsNamespaceExportable(export.Namespace) ||
+			metautil.IsStatusConditionFalse(export.Status.Conditions, string(svcexpv1alpha1.ServiceExportValid)) {
+			continue
+		}
+		active = append(active, export)
+	}
+
+	c.broker.Enqueue(
+		event.Message{
+			Kind: event.ServiceExportsSynced,
+			NewObj: &event.ServiceExportSyncEvent{
+				Geo:            c.connectorConfig,
+				ServiceExports: active,
+			},
+		},
+	)
 }
 
 func (c *RemoteCache) Sync() {
@@ -19,6 +19,7 @@ package cache
 import (
 	"fmt"
 	"github.com/flomesh-io/ErieCanal/pkg/cache/controller"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
 	"github.com/flomesh-io/ErieCanal/pkg/kube"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 	utilcache "k8s.io/kubernetes/pkg/proxy/util"
+	utilnet "k8s.io/utils/net"
 	"net"
 	"reflect"
 	"strings"
@@ -87,8 +89,7 @@ func (sct *ServiceChangeTracker) newBaseServiceInfo(port *corev1.ServicePort, se
 	klog.V(5).Infof("Service %s/%s, Type: %q, Port %s", service.Namespace, service.Name, service.Spec.Type, port.String())
 	switch service.Spec.Type {
 	case corev1.ServiceTypeClusterIP:
-		// ONLY supports IPv4 for now
-		clusterIP := utilcache.GetClusterIPByFamily(corev1.IPv4Protocol, service)
+		clusterIP := utilcache.GetClusterIPByFamily(primaryIPFamily(service), service)
 		info := &BaseServiceInfo{
 			//address:  netutils.ParseIPSloppy(clusterIP),
 			address:  clusterIP,
@@ -192,7 +193,7 @@ func (sct *ServiceChangeTracker) serviceToServiceMap(service *corev1.Service) Se
 		return nil
 	}
 
-	clusterIP := utilcache.GetClusterIPByFamily(corev1.IPv4Protocol, service)
+	clusterIP := utilcache.GetClusterIPByFamily(primaryIPFamily(service), service)
 	if clusterIP == "" {
 		return nil
 	}
@@ -227,6 +228,12 @@ func (sct *ServiceChangeTracker) shouldSkipService(svc *corev1.Service) bool {
 	//	return true
 	//}
 
+	// Service derived from ServiceImport is a shadow of the ServiceImport, the routes are built from ServiceImport
+	if _, ok := svc.Labels[commons.ServiceNameLabel]; ok {
+		klog.V(5).Infof("Service %s/%s is ignored as it's derived from ServiceImport", svc.Namespace, svc.Name)
+		return true
+	}
+
 	switch svc.Spec.Type {
 	// ignore NodePort and LoadBalancer service
 	case corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
@@ -313,3 +320,17 @@ func enrichServiceInfo(port *corev1.ServicePort, service *corev1.Service, baseIn
 
 	return info
 }
+
+// primaryIPFamily returns the primary IP family of the Service, the Service of an IPv6-only
+// or IPv6-primary dual-stack cluster is routed by its IPv6 ClusterIP
+func primaryIPFamily(service *corev1.Service) corev1.IPFamily {
+	if len(service.Spec.IPFamilies) > 0 {
+		return service.Spec.IPFamilies[0]
+	}
+
+	if utilnet.IsIPv6String(service.Spec.ClusterIP) {
+		return corev1.IPv6Protocol
+	}
+
+	return corev1.IPv4Protocol
+}
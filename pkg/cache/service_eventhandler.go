@@ -17,8 +17,10 @@
 package cache
 
 import (
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
+	"reflect"
 )
 
 func (c *LocalCache) OnServiceAdd(service *corev1.Service) {
@@ -30,6 +32,36 @@ func (c *LocalCache) OnServiceUpdate(oldService, service *corev1.Service) {
 		klog.V(5).Infof("Detects service change, syncing...")
 		c.Sync()
 	}
+
+	if c.refreshDerivedServiceImport(oldService, service) && c.isInitialized() {
+		klog.V(5).Infof("Detects derived service change, syncing...")
+		c.Sync()
+	}
+}
+
+// refreshDerivedServiceImport refreshes the ServiceImport which the Service is derived from,
+// as the ServiceImport uses ClusterIP of the derived Service
+func (c *LocalCache) refreshDerivedServiceImport(oldService, service *corev1.Service) bool {
+	svc := service
+	if svc == nil {
+		svc = oldService
+	}
+
+	name, ok := svc.Labels[commons.ServiceNameLabel]
+	if !ok {
+		return false
+	}
+
+	if oldService != nil && service != nil && reflect.DeepEqual(oldService.Spec.ClusterIPs, service.Spec.ClusterIPs) {
+		return false
+	}
+
+	svcImp, err := c.controllers.ServiceImport.Lister.ServiceImports(svc.Namespace).Get(name)
+	if err != nil {
+		return false
+	}
+
+	return c.serviceImportChanges.Update(nil, svcImp)
 }
 
 func (c *LocalCache) OnServiceDelete(service *corev1.Service) {
@@ -39,7 +71,7 @@ func (c *LocalCache) OnServiceDelete(service *corev1.Service) {
 func (c *LocalCache) OnServiceSynced() {
 	c.mu.Lock()
 	c.servicesSynced = true
-	c.setInitialized(c.serviceImportSynced && c.endpointsSynced && c.ingressesSynced && c.ingressClassesSynced)
+	c.setInitialized(c.serviceImportSynced && c.endpointsSynced && c.ingressesSynced && c.ingressClassesSynced && c.gtpSynced)
 	c.mu.Unlock()
 
 	c.syncRoutes()
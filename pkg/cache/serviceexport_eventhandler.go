@@ -22,6 +22,7 @@ import (
 	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
 	"github.com/flomesh-io/ErieCanal/pkg/event"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,9 +42,27 @@ func (c *RemoteCache) OnServiceExportUpdate(oldExport, export *svcexpv1alpha1.Se
 		return
 	}
 
+	// the import status is reported by connectors, it doesn't affect importing
+	if importStatusChangedOnly(oldExport, export) {
+		klog.V(5).Infof("[%s] OnServiceExportUpdate %s is ignored as only import status changes", c.connectorConfig.Key(), client.ObjectKeyFromObject(export))
+		return
+	}
+
 	c.OnUpdate(oldExport, export)
 }
 
+func importStatusChangedOnly(oldExport, export *svcexpv1alpha1.ServiceExport) bool {
+	oldCopy := oldExport.DeepCopy()
+	newCopy := export.DeepCopy()
+	for _, exp := range []*svcexpv1alpha1.ServiceExport{oldCopy, newCopy} {
+		exp.ResourceVersion = ""
+		exp.ManagedFields = nil
+		exp.Status.Clusters = nil
+	}
+
+	return equality.Semantic.DeepEqual(oldCopy, newCopy)
+}
+
 func (c *RemoteCache) OnUpdate(oldExport, export *svcexpv1alpha1.ServiceExport) {
 	mc := c.clusterCfg.MeshConfig.GetConfig()
 	if !mc.IsManaged {
@@ -51,6 +70,13 @@ func (c *RemoteCache) OnUpdate(oldExport, export *svcexpv1alpha1.ServiceExport)
 		return
 	}
 
+	// the policy may be changed after the export is admitted, stop importing it
+	if !mc.IsNamespaceExportable(export.Namespace) {
+		klog.Warningf("[%s] Namespace %s is not allowed to export services, withdraw ServiceExport %s", c.connectorConfig.Key(), export.Namespace, client.ObjectKeyFromObject(export))
+		c.OnServiceExportDelete(export)
+		return
+	}
+
 	svc, err := c.getService(export)
 	if err != nil {
 		klog.Errorf("[%s] Ignore processing ServiceExport %s", c.connectorConfig.Key(), client.ObjectKeyFromObject(export))
@@ -27,7 +27,9 @@ import (
 	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 	utilcache "k8s.io/kubernetes/pkg/proxy/util"
+	"net"
 	"reflect"
+	"strconv"
 	"sync"
 )
 
@@ -95,10 +97,15 @@ func (sct *ServiceImportChangeTracker) newBaseServiceInfo(port *svcimpv1alpha1.S
 	klog.V(5).Infof("ServiceImport %s/%s, Port %s", svcImp.Namespace, svcImp.Name, port.String())
 
 	clusterIP := ""
-	svc, exists := sct.serviceExists(svcImp)
-	if exists {
-		// ONLY supports IPv4 for now, uses Service ClusterIP, if a Service with same name exists
-		clusterIP = utilcache.GetClusterIPByFamily(corev1.IPv4Protocol, svc)
+	if svcImp.Spec.Type == svcimpv1alpha1.ClusterSetIP && len(svcImp.Spec.IPs) > 0 {
+		// uses the VIP allocated to ServiceImport
+		clusterIP = svcImp.Spec.IPs[0]
+	} else if svc, exists := sct.derivedServiceExists(svcImp); exists {
+		// uses ClusterIP of the Service derived from ServiceImport
+		clusterIP = utilcache.GetClusterIPByFamily(primaryIPFamily(svc), svc)
+	} else if svc, exists := sct.serviceExists(svcImp); exists {
+		// uses Service ClusterIP, if a Service with same name exists
+		clusterIP = utilcache.GetClusterIPByFamily(primaryIPFamily(svc), svc)
 	}
 
 	info := &BaseServiceInfo{
@@ -261,6 +268,15 @@ func (sct *ServiceImportChangeTracker) serviceExists(svcImp *svcimpv1alpha1.Serv
 	return svc, true
 }
 
+func (sct *ServiceImportChangeTracker) derivedServiceExists(svcImp *svcimpv1alpha1.ServiceImport) (*corev1.Service, bool) {
+	svc, err := sct.controllers.Service.Lister.Services(svcImp.Namespace).Get(svcImp.DerivedServiceName())
+	if err != nil {
+		return nil, false
+	}
+
+	return svc, true
+}
+
 func shouldSkipServiceImport(svcImp *svcimpv1alpha1.ServiceImport) bool {
 	if svcImp == nil {
 		return true
@@ -275,6 +291,7 @@ func (sct *ServiceImportChangeTracker) endpointsToEndpointsMap(svcImp *svcimpv1a
 	}
 
 	endpointsMap := make(MultiClusterEndpointsMap)
+	family := sct.ipFamilyOf(svcImp)
 	for _, port := range svcImp.Spec.Ports {
 		svcPortName := ServicePortName{
 			NamespacedName: types.NamespacedName{Namespace: svcImp.Namespace, Name: svcImp.Name},
@@ -282,7 +299,7 @@ func (sct *ServiceImportChangeTracker) endpointsToEndpointsMap(svcImp *svcimpv1a
 			Protocol:       port.Protocol,
 		}
 		for _, ep := range port.Endpoints {
-			baseEndpointInfo := newMultiClusterEndpointInfo(&ep, ep.Target)
+			baseEndpointInfo := newMultiClusterEndpointInfo(&ep, ep.Target, family)
 			if sct.enrichEndpointInfo != nil {
 				endpointsMap[svcPortName] = append(endpointsMap[svcPortName], sct.enrichEndpointInfo(baseEndpointInfo))
 			} else {
@@ -295,10 +312,30 @@ func (sct *ServiceImportChangeTracker) endpointsToEndpointsMap(svcImp *svcimpv1a
 	return endpointsMap
 }
 
-func newMultiClusterEndpointInfo(ep *svcimpv1alpha1.Endpoint, target svcimpv1alpha1.Target) *BaseEndpointInfo {
+// ipFamilyOf returns the primary IP family of the Service derived from ServiceImport,
+// the gateway IP of the family is used if an endpoint has no host
+func (sct *ServiceImportChangeTracker) ipFamilyOf(svcImp *svcimpv1alpha1.ServiceImport) corev1.IPFamily {
+	if svc, exists := sct.derivedServiceExists(svcImp); exists {
+		return primaryIPFamily(svc)
+	}
+
+	return corev1.IPv4Protocol
+}
+
+func newMultiClusterEndpointInfo(ep *svcimpv1alpha1.Endpoint, target svcimpv1alpha1.Target, family corev1.IPFamily) *BaseEndpointInfo {
+	host := target.Host
+	if host == "" {
+		host = target.IPOfFamily(family)
+	}
+	if host == "" {
+		host = target.IP
+	}
+
 	return &BaseEndpointInfo{
-		Endpoint: fmt.Sprintf("%s:%d%s", target.Host, target.Port, target.Path),
+		Endpoint: net.JoinHostPort(host, strconv.Itoa(int(target.Port))) + target.Path,
+		Hostname: ep.Hostname,
 		Cluster:  ep.ClusterKey,
+		TLS:      target.TLS,
 	}
 }
 
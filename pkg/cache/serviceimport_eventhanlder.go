@@ -18,6 +18,7 @@ package cache
 
 import (
 	"github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/klog/v2"
 )
 
@@ -29,6 +30,14 @@ func (c *LocalCache) OnServiceImportUpdate(oldServiceImport, serviceImport *v1al
 	if c.serviceImportChanges.Update(oldServiceImport, serviceImport) && c.isInitialized() {
 		klog.V(5).Infof("Detects ServiceImport change, syncing...")
 		c.Sync()
+		return
+	}
+
+	// ejections of outlier detection are recorded in status
+	if oldServiceImport != nil && serviceImport != nil && c.isInitialized() &&
+		!equality.Semantic.DeepEqual(oldServiceImport.Status, serviceImport.Status) {
+		klog.V(5).Infof("Detects ServiceImport status change, syncing...")
+		c.Sync()
 	}
 }
 
@@ -39,7 +48,7 @@ func (c *LocalCache) OnServiceImportDelete(serviceImport *v1alpha1.ServiceImport
 func (c *LocalCache) OnServiceImportSynced() {
 	c.mu.Lock()
 	c.serviceImportSynced = true
-	c.setInitialized(c.servicesSynced && c.endpointsSynced && c.ingressesSynced && c.ingressClassesSynced)
+	c.setInitialized(c.servicesSynced && c.endpointsSynced && c.ingressesSynced && c.ingressClassesSynced && c.gtpSynced)
 	c.mu.Unlock()
 
 	c.syncRoutes()
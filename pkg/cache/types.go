@@ -18,6 +18,7 @@ package cache
 
 import (
 	"fmt"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
 	gwcontrollerv1beta1 "github.com/flomesh-io/ErieCanal/pkg/controller/gateway/v1beta1"
 	"github.com/flomesh-io/ErieCanal/pkg/route"
 	v1 "k8s.io/api/core/v1"
@@ -76,6 +77,7 @@ type Endpoint interface {
 	NodeName() string
 	HostName() string
 	ClusterInfo() string
+	GatewayTLS() *svcimpv1alpha1.TargetTLS
 	Equal(Endpoint) bool
 }
 
@@ -30,6 +30,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"time"
 )
 
 func NewConfig(k8sApi *kube.K8sAPI, mc *config.MeshConfig) *Config {
@@ -54,6 +55,14 @@ func (c *Config) GetCertificateManager() (certificate.Manager, error) {
 }
 
 func (c *Config) getArchonCertificateManager() (certificate.Manager, error) {
+	intermediate, err := c.getIntermediateCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if intermediate != nil {
+		return newReloadingManager(c, intermediate)
+	}
+
 	rootCert, err := archon.NewRootCA(
 		commons.DefaultCACommonName, commons.DefaultCAValidityPeriod,
 		commons.DefaultCACountry, commons.DefaultCALocality, commons.DefaultCAOrganization,
@@ -71,7 +80,15 @@ func (c *Config) getArchonCertificateManager() (certificate.Manager, error) {
 }
 
 func (c *Config) getCertManagerCertificateManager() (certificate.Manager, error) {
-	client := certmanager.NewClient(c.k8sApi, c.mc)
+	intermediate, err := c.getIntermediateCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if intermediate != nil {
+		return newReloadingManager(c, intermediate)
+	}
+
+	client := c.certManagerClient()
 
 	rootCert, err := certmanager.NewRootCA(
 		client,
@@ -85,6 +102,67 @@ func (c *Config) getCertManagerCertificateManager() (certificate.Manager, error)
 	return certmanager.NewManager(rootCert, client)
 }
 
+// certManagerClient returns the client of cert-manager, it's created once as it runs informers
+func (c *Config) certManagerClient() *certmanager.Client {
+	c.cmClientOnce.Do(func() {
+		c.cmClient = certmanager.NewClient(c.k8sApi, c.mc)
+	})
+
+	return c.cmClient
+}
+
+// newIntermediateManager returns the manager which signs certificates by the intermediate CA of ClusterSet
+func (c *Config) newIntermediateManager(intermediate *certificate.Certificate) (certificate.Manager, error) {
+	switch certificate.CertificateManagerType(c.mc.Certificate.Manager) {
+	case certificate.Archon:
+		return archon.NewManager(intermediate)
+	case certificate.CertManager:
+		return certmanager.NewIntermediateManager(intermediate, c.certManagerClient(), commons.ClusterCASecretName)
+	default:
+		return nil, fmt.Errorf("%q doesn't support intermediate CA", c.mc.Certificate.Manager)
+	}
+}
+
+// getIntermediateCertificate returns the intermediate CA issued by the control plane of ClusterSet,
+// it's nil if the cluster doesn't join any ClusterSet. The CA of returned certificate is the trust bundle.
+// An expired intermediate CA is an error, signing by a standalone root silently breaks the trust of ClusterSet.
+func (c *Config) getIntermediateCertificate() (*certificate.Certificate, error) {
+	ns := c.mc.GetCaBundleNamespace()
+	secret, err := c.k8sApi.Client.CoreV1().Secrets(ns).Get(context.TODO(), commons.ClusterCASecretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get intermediate CA, %s", err.Error())
+	}
+
+	for _, key := range []string{commons.RootCACertName, commons.TLSCertName, commons.TLSPrivateKeyName} {
+		if len(secret.Data[key]) == 0 {
+			klog.Errorf("Secret %s/%s doesn't have required %q data", ns, commons.ClusterCASecretName, key)
+			return nil, fmt.Errorf("invalid secret data for cert")
+		}
+	}
+
+	x509Cert, err := utils.ConvertPEMCertToX509(secret.Data[commons.TLSCertName])
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(x509Cert.NotAfter) {
+		return nil, fmt.Errorf("intermediate CA %s/%s expired at %s, it's renewed by the control plane of ClusterSet", ns, commons.ClusterCASecretName, x509Cert.NotAfter)
+	}
+
+	klog.V(2).Infof("Signing certificates by intermediate CA %q of ClusterSet", x509Cert.Subject.CommonName)
+	return &certificate.Certificate{
+		CommonName:   x509Cert.Subject.CommonName,
+		SerialNumber: x509Cert.SerialNumber.String(),
+		CA:           secret.Data[commons.RootCACertName],
+		CrtPEM:       secret.Data[commons.TLSCertName],
+		KeyPEM:       secret.Data[commons.TLSPrivateKeyName],
+		Expiration:   x509Cert.NotAfter,
+	}, nil
+}
+
 func (c *Config) getManualCertificateManager() (certificate.Manager, error) {
 	panic("Not implemented yet.")
 }
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"github.com/flomesh-io/ErieCanal/pkg/certificate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sync"
+	"time"
+)
+
+// intermediateReloadInterval is the interval of checking whether the intermediate CA is renewed
+const intermediateReloadInterval = 5 * time.Minute
+
+// reloadingManager signs certificates by the intermediate CA of ClusterSet, the CA is reloaded once the
+// control plane renews it. The renewal keeps the trust bundle, so the issued certificates are still
+// trusted and the manager doesn't need to restart. It's restarted by the control plane if the trust changes.
+type reloadingManager struct {
+	config *Config
+
+	mu           sync.RWMutex
+	manager      certificate.Manager
+	intermediate *certificate.Certificate
+}
+
+var _ certificate.Manager = &reloadingManager{}
+
+func newReloadingManager(c *Config, intermediate *certificate.Certificate) (*reloadingManager, error) {
+	manager, err := c.newIntermediateManager(intermediate)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &reloadingManager{
+		config:       c,
+		manager:      manager,
+		intermediate: intermediate,
+	}
+	go wait.Forever(m.reload, intermediateReloadInterval)
+
+	return m, nil
+}
+
+func (m *reloadingManager) reload() {
+	intermediate, err := m.config.getIntermediateCertificate()
+	if err != nil {
+		klog.Errorf("Failed to reload intermediate CA: %s", err)
+		return
+	}
+
+	m.mu.RLock()
+	current := m.intermediate
+	m.mu.RUnlock()
+
+	switch {
+	case intermediate == nil:
+		// the cluster leaves the ClusterSet, the control plane restarts the manager
+		return
+	case intermediate.SerialNumber == current.SerialNumber:
+		return
+	case !bytes.Equal(intermediate.CA, current.CA):
+		klog.Warningf("Trust bundle of intermediate CA %q is changed, it takes effect after restarting", intermediate.CommonName)
+		return
+	}
+
+	manager, err := m.config.newIntermediateManager(intermediate)
+	if err != nil {
+		klog.Errorf("Failed to reload intermediate CA %q: %s", intermediate.CommonName, err)
+		return
+	}
+
+	m.mu.Lock()
+	m.manager = manager
+	m.intermediate = intermediate
+	m.mu.Unlock()
+
+	klog.Infof("Reloaded intermediate CA %q, it expires at %s", intermediate.CommonName, intermediate.Expiration)
+}
+
+func (m *reloadingManager) current() certificate.Manager {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.manager
+}
+
+func (m *reloadingManager) IssueCertificate(cn string, validityPeriod time.Duration, dnsNames []string) (*certificate.Certificate, error) {
+	return m.current().IssueCertificate(cn, validityPeriod, dnsNames)
+}
+
+func (m *reloadingManager) IssueIntermediateCertificate(cn string, validityPeriod time.Duration) (*certificate.Certificate, error) {
+	return m.current().IssueIntermediateCertificate(cn, validityPeriod)
+}
+
+func (m *reloadingManager) GetCertificate(cn string) (*certificate.Certificate, error) {
+	return m.current().GetCertificate(cn)
+}
+
+func (m *reloadingManager) GetRootCertificate() (*certificate.Certificate, error) {
+	return m.current().GetRootCertificate()
+}
@@ -17,12 +17,17 @@
 package config
 
 import (
+	"github.com/flomesh-io/ErieCanal/pkg/certificate/managers/certmanager"
 	"github.com/flomesh-io/ErieCanal/pkg/config"
 	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"sync"
 )
 
 type Config struct {
 	k8sApi *kube.K8sAPI
 	mc     *config.MeshConfig
 	//managerType certificate.CertificateManagerType
+
+	cmClientOnce sync.Once
+	cmClient     *certmanager.Client
 }
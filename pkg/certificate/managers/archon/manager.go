@@ -17,6 +17,7 @@
 package archon
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -113,54 +114,101 @@ func (m *ArchonManager) IssueCertificate(cn string, validityPeriod time.Duration
 		BasicConstraintsValid: true,
 	}
 
-	// ROOT CA
-	rootCert, err := utils.ConvertPEMCertToX509(m.ca.CrtPEM)
+	// TLS private key
+	tlsKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("generate private key: %s", err.Error())
 	}
 
-	// ROOT private key
-	rootKey, err := utils.ConvertPEMPrivateKeyToX509(m.ca.KeyPEM)
+	issuedCert, err := m.sign(&cert, tlsKey)
 	if err != nil {
 		return nil, err
 	}
 
-	// TLS private key
-	tlsKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	m.certificates[cn] = issuedCert
+
+	return issuedCert, nil
+}
+
+func (m *ArchonManager) IssueIntermediateCertificate(cn string, validityPeriod time.Duration) (*certificate.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, certificate.SerialNumberLimit)
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %s", err.Error())
+	}
+
+	now := time.Now()
+	ca := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   cn,
+			Organization: []string{commons.DefaultCAOrganization},
+		},
+		NotBefore:             now,
+		NotAfter:              now.Add(validityPeriod),
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	// CA private key
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		return nil, fmt.Errorf("generate private key: %s", err.Error())
 	}
 
+	return m.sign(&ca, caKey)
+}
+
+// sign signs the certificate by the CA of manager, the CA certificate is appended to the issued one
+// if it's an intermediate, so that the chain is able to be verified by the trust bundle
+func (m *ArchonManager) sign(cert *x509.Certificate, key *rsa.PrivateKey) (*certificate.Certificate, error) {
+	// Signing CA
+	caCert, err := utils.ConvertPEMCertToX509(m.ca.CrtPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	// Signing CA private key
+	caKey, err := utils.ConvertPEMPrivateKeyToX509(m.ca.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	// a certificate never outlives the CA which signs it
+	if cert.NotAfter.After(caCert.NotAfter) {
+		cert.NotAfter = caCert.NotAfter
+	}
+
 	// sign the certificate
-	tlsBytes, err := x509.CreateCertificate(rand.Reader, &cert, rootCert, &tlsKey.PublicKey, rootKey)
+	certBytes, err := x509.CreateCertificate(rand.Reader, cert, caCert, &key.PublicKey, caKey)
 	if err != nil {
 		return nil, fmt.Errorf("create cert: %s", err.Error())
 	}
 
 	// PEM encode cert
-	pemTlsCert, err := utils.CertToPEM(tlsBytes)
+	pemCert, err := utils.CertToPEM(certBytes)
 	if err != nil {
 		return nil, err
 	}
+	if !bytes.Equal(m.ca.CrtPEM, m.ca.CA) {
+		pemCert = append(pemCert, m.ca.CrtPEM...)
+	}
 
 	// PEM encode key
-	pemTlsKey, err := utils.RSAKeyToPEM(tlsKey)
+	pemKey, err := utils.RSAKeyToPEM(key)
 	if err != nil {
 		return nil, err
 	}
 
-	issuedCert := &certificate.Certificate{
-		CommonName:   cn,
-		SerialNumber: serialNumber.String(),
-		CA:           m.ca.CrtPEM,
-		CrtPEM:       pemTlsCert,
-		KeyPEM:       pemTlsKey,
+	return &certificate.Certificate{
+		CommonName:   cert.Subject.CommonName,
+		SerialNumber: cert.SerialNumber.String(),
+		CA:           m.ca.CA,
+		CrtPEM:       pemCert,
+		KeyPEM:       pemKey,
 		Expiration:   cert.NotAfter,
-	}
-
-	m.certificates[cn] = issuedCert
-
-	return issuedCert, nil
+	}, nil
 }
 
 func (m *ArchonManager) GetCertificate(cn string) (*certificate.Certificate, error) {
@@ -78,6 +78,26 @@ func NewManager(ca *certificate.Certificate, client *Client) (*CertManager, erro
 	}, nil
 }
 
+// NewIntermediateManager returns a CertManager which signs certificates by the intermediate CA
+// in the Secret, the CA is issued by the control plane of ClusterSet
+func NewIntermediateManager(ca *certificate.Certificate, client *Client, secretName string) (*CertManager, error) {
+	issuer, err := caIssuer(client, IntermediateCAIssuerName, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertManager{
+		ca:     ca,
+		client: client,
+		issuerRef: cmmeta.ObjectReference{
+			Kind:  IssuerKind,
+			Name:  issuer.Name,
+			Group: IssuerGroup,
+		},
+		certificates: map[string]*certificate.Certificate{},
+	}, nil
+}
+
 func NewRootCA(
 	client *Client,
 	cn string, validityPeriod time.Duration,
@@ -131,7 +151,7 @@ func NewRootCA(
 	}
 
 	// create cert-manager CA issuer
-	_, err = caIssuer(client)
+	_, err = caIssuer(client, CAIssuerName, client.mc.GetCaBundleName())
 	if err != nil {
 		return nil, err
 	}
@@ -206,16 +226,16 @@ func selfSignedIssuer(c *Client) (*certmgr.Issuer, error) {
 	return issuer, nil
 }
 
-func caIssuer(c *Client) (*certmgr.Issuer, error) {
+func caIssuer(c *Client, name, secretName string) (*certmgr.Issuer, error) {
 	issuer := &certmgr.Issuer{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      CAIssuerName,
+			Name:      name,
 			Namespace: c.ns,
 		},
 		Spec: certmgr.IssuerSpec{
 			IssuerConfig: certmgr.IssuerConfig{
 				CA: &certmgr.CAIssuer{
-					SecretName: c.mc.GetCaBundleName(),
+					SecretName: secretName,
 				},
 			},
 		},
@@ -227,10 +247,10 @@ func caIssuer(c *Client) (*certmgr.Issuer, error) {
 	if err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			// it's normal in case of race condition
-			klog.V(2).Infof("Issuer %s/%s already exists.", c.ns, CAIssuerName)
+			klog.V(2).Infof("Issuer %s/%s already exists.", c.ns, name)
 			issuer, err = c.cmClient.CertmanagerV1().
 				Issuers(c.ns).
-				Get(context.Background(), CAIssuerName, metav1.GetOptions{})
+				Get(context.Background(), name, metav1.GetOptions{})
 			if err != nil {
 				return nil, err
 			}
@@ -299,12 +319,31 @@ func waitingForCAIssued(c *Client) (*certmgr.Certificate, error) {
 }
 
 func (m CertManager) IssueCertificate(cn string, validityPeriod time.Duration, dnsNames []string) (*certificate.Certificate, error) {
-	// TLS private key
-	tlsKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	csr := &x509.CertificateRequest{
+		Version:            3,
+		SignatureAlgorithm: x509.SHA512WithRSA,
+		PublicKeyAlgorithm: x509.RSA,
+		Subject: pkix.Name{
+			CommonName:   cn,
+			Organization: []string{commons.DefaultCAOrganization},
+		},
+		DNSNames: dnsNames,
+	}
+
+	issuedCert, err := m.issue(csr, false, validityPeriod, []certmgr.KeyUsage{
+		certmgr.UsageKeyEncipherment, certmgr.UsageDigitalSignature,
+		certmgr.UsageClientAuth, certmgr.UsageServerAuth,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("generate private key: %s", err.Error())
+		return nil, err
 	}
 
+	m.certificates[issuedCert.CommonName] = issuedCert
+
+	return issuedCert, nil
+}
+
+func (m CertManager) IssueIntermediateCertificate(cn string, validityPeriod time.Duration) (*certificate.Certificate, error) {
 	csr := &x509.CertificateRequest{
 		Version:            3,
 		SignatureAlgorithm: x509.SHA512WithRSA,
@@ -313,7 +352,18 @@ func (m CertManager) IssueCertificate(cn string, validityPeriod time.Duration, d
 			CommonName:   cn,
 			Organization: []string{commons.DefaultCAOrganization},
 		},
-		DNSNames: dnsNames,
+	}
+
+	return m.issue(csr, true, validityPeriod, []certmgr.KeyUsage{
+		certmgr.UsageCertSign, certmgr.UsageCRLSign, certmgr.UsageDigitalSignature,
+	})
+}
+
+func (m CertManager) issue(csr *x509.CertificateRequest, isCA bool, validityPeriod time.Duration, usages []certmgr.KeyUsage) (*certificate.Certificate, error) {
+	// TLS private key
+	tlsKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("generate private key: %s", err.Error())
 	}
 
 	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, csr, tlsKey)
@@ -333,14 +383,11 @@ func (m CertManager) IssueCertificate(cn string, validityPeriod time.Duration, d
 		},
 		Spec: certmgr.CertificateRequestSpec{
 			Request: pemCSR,
-			IsCA:    false,
+			IsCA:    isCA,
 			Duration: &metav1.Duration{
 				Duration: validityPeriod,
 			},
-			Usages: []certmgr.KeyUsage{
-				certmgr.UsageKeyEncipherment, certmgr.UsageDigitalSignature,
-				certmgr.UsageClientAuth, certmgr.UsageServerAuth,
-			},
+			Usages:    usages,
 			IssuerRef: m.issuerRef,
 		},
 	}
@@ -369,18 +416,15 @@ func (m CertManager) IssueCertificate(cn string, validityPeriod time.Duration, d
 		}
 	}()
 
-	issuedCert := &certificate.Certificate{
+	// the CA of an intermediate issuer is the intermediate itself, the trust bundle is used instead
+	return &certificate.Certificate{
 		CommonName:   cert.Subject.CommonName,
 		SerialNumber: cert.SerialNumber.String(),
-		CA:           cr.Status.CA,
+		CA:           m.ca.CA,
 		CrtPEM:       cr.Status.Certificate,
 		KeyPEM:       pemTlsKey,
 		Expiration:   cert.NotAfter,
-	}
-
-	m.certificates[cert.Subject.CommonName] = issuedCert
-
-	return issuedCert, nil
+	}, nil
 }
 
 func (m CertManager) createCertManagerCertificateRequest(certificateRequest *certmgr.CertificateRequest) (*certmgr.CertificateRequest, error) {
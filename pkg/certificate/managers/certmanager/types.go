@@ -32,6 +32,8 @@ const (
 	IssuerKind            = "Issuer"
 	IssuerGroup           = "cert-manager.io"
 	CertManagerRootCAName = "flomesh-root-ca"
+	// IntermediateCAIssuerName is the Issuer of the intermediate CA issued by the control plane of ClusterSet
+	IntermediateCAIssuerName = "cluster.flomesh.io"
 
 	DefaultPollInterval = 1 * time.Second
 	DefaultPollTimeout  = 60 * time.Second
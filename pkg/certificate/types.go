@@ -39,10 +39,14 @@ type Manager interface {
 	// IssueCertificate issues a new certificate.
 	IssueCertificate(cn string, validityPeriod time.Duration, dnsNames []string) (*Certificate, error)
 
+	// IssueIntermediateCertificate issues an intermediate CA which is able to sign certificates on behalf of the root.
+	IssueIntermediateCertificate(cn string, validityPeriod time.Duration) (*Certificate, error)
+
 	// GetCertificate returns a certificate given its Common Name (CN)
 	GetCertificate(cn string) (*Certificate, error)
 
-	// GetRootCertificate returns the root certificate in PEM format and its expiration.
+	// GetRootCertificate returns the root certificate in PEM format and its expiration, the CA of it
+	// is the trust bundle in case of signing by an intermediate CA.
 	GetRootCertificate() (*Certificate, error)
 }
 
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"github.com/flomesh-io/ErieCanal/pkg/certificate/utils"
+	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"strings"
+	"time"
+)
+
+const (
+	clusterCAValidityPeriod = 365 * 24 * time.Hour
+	// clusterCARenewBefore is how long before expiration the intermediate CA is renewed
+	clusterCARenewBefore  = 30 * 24 * time.Hour
+	clusterCASyncInterval = 1 * time.Hour
+)
+
+// syncClusterCAPeriodically keeps the intermediate CA of the cluster valid, it's checked
+// hourly and renewed before expiration
+func (c *RemoteConnector) syncClusterCAPeriodically(stopCh <-chan struct{}) {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	wait.Until(func() {
+		if !c.isActive() {
+			return
+		}
+
+		if err := c.syncClusterCA(); err != nil {
+			klog.Errorf("[%s] Failed to sync intermediate CA: %s", ctx.ClusterKey, err)
+		}
+	}, clusterCASyncInterval, stopCh)
+}
+
+// syncClusterCA issues an intermediate CA to the cluster from the root CA of control plane, so that all
+// clusters of the ClusterSet share the same trust. It's stored in a Secret of the cluster along with the
+// trust bundle. The ErieCanal manager of the cluster reloads the renewed CA by itself, it's restarted only
+// if the trust bundle changes, e.g. the cluster joins the ClusterSet or is taken over by another control plane.
+func (c *RemoteConnector) syncClusterCA() error {
+	ctx := c.context.(*conn.ConnectorContext)
+	ns := c.clusterCfg.MeshConfig.GetConfig().GetCaBundleNamespace()
+
+	secrets := c.k8sAPI.Client.CoreV1().Secrets(ns)
+	secret, err := secrets.Get(context.TODO(), commons.ClusterCASecretName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if exists && !c.shouldRenewClusterCA(secret) {
+		return nil
+	}
+
+	cn := clusterCACommonName(ctx.ClusterKey)
+	ca, err := c.certMgr.IssueIntermediateCertificate(cn, clusterCAValidityPeriod)
+	if err != nil {
+		return err
+	}
+
+	trustChanged := !exists || !bytes.Equal(secret.Data[commons.RootCACertName], ca.CA)
+	data := map[string][]byte{
+		commons.RootCACertName:    ca.CA,
+		commons.TLSCertName:       ca.CrtPEM,
+		commons.TLSPrivateKeyName: ca.KeyPEM,
+	}
+
+	if exists {
+		secret.Data = data
+		if _, err := secrets.Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	} else {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      commons.ClusterCASecretName,
+				Namespace: ns,
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+		if _, err := secrets.Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	klog.Infof("[%s] Issued intermediate CA %s, it expires at %s", ctx.ClusterKey, cn, ca.Expiration)
+
+	if !trustChanged {
+		return nil
+	}
+
+	return c.restartManager()
+}
+
+// shouldRenewClusterCA returns true if the intermediate CA is about to expire, or it doesn't chain up to
+// the root CA of this control plane, e.g. the cluster is taken over from another control plane
+func (c *RemoteConnector) shouldRenewClusterCA(secret *corev1.Secret) bool {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	root, err := c.certMgr.GetRootCertificate()
+	if err != nil {
+		klog.Errorf("[%s] Failed to get root certificate: %s", ctx.ClusterKey, err)
+		return false
+	}
+
+	ca, err := utils.ConvertPEMCertToX509(secret.Data[commons.TLSCertName])
+	if err != nil {
+		klog.Warningf("[%s] Invalid intermediate CA, renew it: %s", ctx.ClusterKey, err)
+		return true
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(root.CA) {
+		klog.Errorf("[%s] Invalid root certificate of control plane", ctx.ClusterKey)
+		return false
+	}
+	if _, err := ca.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		klog.Infof("[%s] Intermediate CA isn't issued by the root CA of control plane, renew it: %s", ctx.ClusterKey, err)
+		return true
+	}
+
+	return !ca.IsCA ||
+		time.Now().Add(clusterCARenewBefore).After(ca.NotAfter) ||
+		!strings.EqualFold(ca.Subject.CommonName, clusterCACommonName(ctx.ClusterKey))
+}
+
+// deleteClusterCA removes the intermediate CA from the cluster once it leaves the ClusterSet,
+// the cluster signs certificates by its own root CA after restarting
+func (c *RemoteConnector) deleteClusterCA() error {
+	ns := c.clusterCfg.MeshConfig.GetConfig().GetCaBundleNamespace()
+
+	err := c.k8sAPI.Client.CoreV1().
+		Secrets(ns).
+		Delete(context.TODO(), commons.ClusterCASecretName, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	return c.restartManager()
+}
+
+// restartManager rolls out the ErieCanal manager of the cluster, the certificate manager is
+// initialized on start so that it's the way to reload the CA
+func (c *RemoteConnector) restartManager() error {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	// patch the deployment spec template triggers the action of rollout restart like with kubectl
+	patch := fmt.Sprintf(
+		`{"spec": {"template":{"metadata": {"annotations": {"kubectl.kubernetes.io/restartedAt": "%s"}}}}}`,
+		time.Now().Format(commons.ProxyProfileLastUpdatedTimeFormat),
+	)
+
+	klog.Infof("[%s] Restarting ErieCanal manager to reload CA ...", ctx.ClusterKey)
+	_, err := c.k8sAPI.Client.AppsV1().
+		Deployments(config.GetErieCanalNamespace()).
+		Patch(context.TODO(), commons.ManagerDeploymentName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+
+	return err
+}
+
+// clusterCACommonName is the CN of the intermediate CA of a cluster, e.g.
+// cluster1.default.default.default.flomesh.io
+func clusterCACommonName(clusterKey string) string {
+	return fmt.Sprintf("%s.%s", reversedClusterKey(clusterKey), commons.DefaultCACommonName)
+}
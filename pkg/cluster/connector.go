@@ -76,11 +76,14 @@ func NewConnector(ctx context.Context, broker *event.Broker, certMgr certificate
 		}, nil
 	} else {
 		return &RemoteConnector{
-			context:    connectorCtx,
-			k8sAPI:     k8sAPI,
-			cache:      connectorCache,
-			clusterCfg: clusterCfg,
-			broker:     broker,
+			context:       connectorCtx,
+			k8sAPI:        k8sAPI,
+			cache:         connectorCache,
+			clusterCfg:    clusterCfg,
+			broker:        broker,
+			certMgr:       certMgr,
+			pending:       make(map[string]*event.ServiceExportEvent),
+			clusterHealth: make(map[string]bool),
 		}, nil
 	}
 }
@@ -29,8 +29,13 @@ type ConnectorContext struct {
 	SpecHash        string
 	KubeConfig      *rest.Config
 	ConnectorConfig *config.ConnectorConfig
-	Cancel          func()
-	StopCh          chan struct{}
+	ControlPlane    config.ClusterSetControlPlane
+	GatewayResolver config.ClusterSetGatewayResolver
+	GatewayTLS      config.ClusterSetGatewayTLS
+	// ClusterSetConfig is the MeshConfig of control plane, the namespace mapping in it applies ClusterSet-wide
+	ClusterSetConfig *config.MeshConfigClient
+	Cancel           func()
+	StopCh           chan struct{}
 }
 
 // ConnectorCtxKey the pointer is the key that a ConnectorContext returns itself for.
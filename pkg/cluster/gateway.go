@@ -0,0 +1,238 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"bufio"
+	"fmt"
+	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"golang.org/x/net/dns/dnsmessage"
+	"k8s.io/klog/v2"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// minResolveInterval avoids hammering DNS servers if records have tiny TTL
+	minResolveInterval = 5 * time.Second
+	resolvConf         = "/etc/resolv.conf"
+	dnsTimeout         = 3 * time.Second
+)
+
+// resolveGateway re-resolves the gateway host of the cluster if it's a DNS name. The cloud load
+// balancers may change IPs, once the gateway IPs are changed the services of the cluster are
+// re-exported, so that the endpoints of ServiceImports in all clusters are updated.
+func (c *RemoteConnector) resolveGateway(stopCh <-chan struct{}) {
+	ctx := c.context.(*conn.ConnectorContext)
+	connectorCfg := ctx.ConnectorConfig
+
+	if !connectorCfg.IsGatewayDNSName() {
+		return
+	}
+
+	timer := time.NewTimer(c.resolveInterval())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			c.refreshGatewayIPs()
+			timer.Reset(c.resolveInterval())
+		case <-stopCh:
+			klog.Infof("[%s] Stop resolving gateway host.", connectorCfg.Key())
+			return
+		}
+	}
+}
+
+func (c *RemoteConnector) refreshGatewayIPs() {
+	ctx := c.context.(*conn.ConnectorContext)
+	connectorCfg := ctx.ConnectorConfig
+	host := connectorCfg.GatewayHost()
+
+	ips, err := config.ResolveGatewayIPs(host)
+	if err != nil {
+		klog.Warningf("[%s] Failed to re-resolve gateway host, keep using %v: %s", connectorCfg.Key(), connectorCfg.GatewayIPs(), err)
+		return
+	}
+
+	old := connectorCfg.GatewayIPs()
+	if !connectorCfg.SetGatewayIPs(ips) {
+		return
+	}
+	klog.Infof("[%s] Gateway host %q is re-resolved from %v to %v", connectorCfg.Key(), host, old, ips)
+
+	// the active control plane updates endpoints, a standby one uses the latest IPs once it takes over
+	if c.isActive() {
+		c.reExportServices()
+	}
+}
+
+// resolveInterval returns the interval before next resolution, it's the TTL of the DNS records
+// if it's shorter than the configured interval
+func (c *RemoteConnector) resolveInterval() time.Duration {
+	ctx := c.context.(*conn.ConnectorContext)
+	interval := ctx.GatewayResolver.Interval()
+
+	ttl, err := lookupTTL(ctx.ConnectorConfig.GatewayHost())
+	if err != nil {
+		klog.V(5).Infof("[%s] Failed to get TTL of gateway host: %s", ctx.ClusterKey, err)
+		return interval
+	}
+
+	if ttl < interval {
+		interval = ttl
+	}
+	if interval < minResolveInterval {
+		interval = minResolveInterval
+	}
+
+	return interval
+}
+
+// lookupTTL returns the minimal TTL of the A/AAAA records of the host. The resolver of Go doesn't
+// expose TTL, the records are queried from the nameservers in resolv.conf directly.
+func lookupTTL(host string) (time.Duration, error) {
+	servers, err := nameservers()
+	if err != nil {
+		return 0, err
+	}
+
+	// gateway hosts are fully qualified, search domains don't apply
+	if !strings.HasSuffix(host, ".") {
+		host = host + "."
+	}
+	name, err := dnsmessage.NewName(host)
+	if err != nil {
+		return 0, err
+	}
+
+	var lastErr error
+	for _, server := range servers {
+		ttl := uint32(0)
+		found := false
+		for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+			t, ok, err := queryTTL(server, name, qtype)
+			if err != nil {
+				lastErr = err
+				found = false
+				break
+			}
+			if ok && (!found || t < ttl) {
+				ttl, found = t, true
+			}
+		}
+
+		if found {
+			return time.Duration(ttl) * time.Second, nil
+		}
+	}
+
+	if lastErr != nil {
+		return 0, lastErr
+	}
+
+	return 0, fmt.Errorf("no A/AAAA record of %s", host)
+}
+
+// queryTTL sends a query of the type to the nameserver, it returns the minimal TTL of answers
+// and false if there's no answer
+func queryTTL(server string, name dnsmessage.Name, qtype dnsmessage.Type) (uint32, bool, error) {
+	id := uint16(rand.Intn(1 << 16))
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		return 0, false, err
+	}
+
+	dnsConn, err := net.DialTimeout("udp", net.JoinHostPort(server, "53"), dnsTimeout)
+	if err != nil {
+		return 0, false, err
+	}
+	defer dnsConn.Close()
+
+	if err := dnsConn.SetDeadline(time.Now().Add(dnsTimeout)); err != nil {
+		return 0, false, err
+	}
+	if _, err := dnsConn.Write(query); err != nil {
+		return 0, false, err
+	}
+
+	buf := make([]byte, 1232)
+	n, err := dnsConn.Read(buf)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(buf[:n]); err != nil {
+		return 0, false, err
+	}
+	if resp.ID != id {
+		return 0, false, fmt.Errorf("mismatched DNS response id %d", resp.ID)
+	}
+	if resp.RCode != dnsmessage.RCodeSuccess {
+		return 0, false, fmt.Errorf("DNS query of %s failed: %s", name, resp.RCode)
+	}
+
+	ttl := uint32(0)
+	found := false
+	for _, answer := range resp.Answers {
+		// the TTL of CNAMEs in the chain counts as well
+		switch answer.Header.Type {
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA, dnsmessage.TypeCNAME:
+			if !found || answer.Header.TTL < ttl {
+				ttl, found = answer.Header.TTL, true
+			}
+		}
+	}
+
+	return ttl, found, nil
+}
+
+// nameservers returns the nameservers in resolv.conf
+func nameservers() ([]string, error) {
+	f, err := os.Open(resolvConf)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	servers := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no nameserver in %s", resolvConf)
+	}
+
+	return servers, nil
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/event"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sync/atomic"
+	"time"
+)
+
+// heartbeat renews the control plane Lease in the member cluster periodically. The Lease fences
+// control planes, the connector federates services of the cluster only if it holds the Lease.
+// Once the holder misses heartbeats for the lease duration, another control plane takes over.
+func (c *RemoteConnector) heartbeat(stopCh <-chan struct{}) {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	wait.Until(func() {
+		if c.renewLease() {
+			c.onActivated()
+		}
+	}, ctx.ControlPlane.HeartbeatInterval(), stopCh)
+}
+
+// renewLease acquires or renews the control plane Lease of the cluster,
+// it returns true if the Lease is just acquired
+func (c *RemoteConnector) renewLease() bool {
+	ctx := c.context.(*conn.ConnectorContext)
+	connectorCfg := ctx.ConnectorConfig
+	cpCfg := ctx.ControlPlane
+	identity := connectorCfg.ControlPlaneUID()
+	leaseDurationSeconds := int32(cpCfg.LeaseDuration().Seconds())
+	leases := c.k8sAPI.Client.CoordinationV1().Leases(config.GetErieCanalNamespace())
+
+	if atomic.LoadInt32(&c.left) == 1 {
+		return false
+	}
+
+	// the lease is valid since the time before sending the request, not the time of response
+	now := time.Now()
+	wasActive := c.isActive()
+
+	lease, err := leases.Get(context.TODO(), commons.ControlPlaneLeaseName, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("[%s] Failed to get control plane Lease: %s", connectorCfg.Key(), err)
+			return false
+		}
+
+		if !c.isClaimable() {
+			return false
+		}
+
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      commons.ControlPlaneLeaseName,
+				Namespace: config.GetErieCanalNamespace(),
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       pointer.String(identity),
+				LeaseDurationSeconds: pointer.Int32(leaseDurationSeconds),
+				AcquireTime:          &metav1.MicroTime{Time: now},
+				RenewTime:            &metav1.MicroTime{Time: now},
+			},
+		}
+		if _, err := leases.Create(context.TODO(), lease, metav1.CreateOptions{}); err != nil {
+			klog.Errorf("[%s] Failed to create control plane Lease: %s", connectorCfg.Key(), err)
+			return false
+		}
+	} else {
+		holder := pointer.StringDeref(lease.Spec.HolderIdentity, "")
+		if holder != identity {
+			if !c.isLeaseExpired(lease, now) {
+				c.setActiveUntil(time.Time{})
+				return false
+			}
+
+			klog.Warningf("[%s] Control plane %q missed heartbeats, taking over the cluster ...", connectorCfg.Key(), holder)
+			lease.Spec.HolderIdentity = pointer.String(identity)
+			lease.Spec.AcquireTime = &metav1.MicroTime{Time: now}
+			lease.Spec.LeaseTransitions = pointer.Int32(pointer.Int32Deref(lease.Spec.LeaseTransitions, 0) + 1)
+		}
+		lease.Spec.LeaseDurationSeconds = pointer.Int32(leaseDurationSeconds)
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: now}
+
+		// the update is rejected if another control plane changes the Lease in between
+		if _, err := leases.Update(context.TODO(), lease, metav1.UpdateOptions{}); err != nil {
+			klog.Errorf("[%s] Failed to renew control plane Lease: %s", connectorCfg.Key(), err)
+			return false
+		}
+	}
+
+	c.setActiveUntil(now.Add(cpCfg.LeaseDuration()))
+	if !wasActive {
+		klog.Infof("[%s] Acquired control plane Lease", connectorCfg.Key())
+	}
+
+	return !wasActive
+}
+
+// onActivated takes over the cluster once the Lease is acquired, the ControlPlaneUID of the cluster
+// is re-stamped. The events dropped while standing by are replayed: all clusters export their services
+// again so that they're imported into it and the import status is reported again, the health of other
+// clusters is re-applied, and the imports of deleted exports are pruned by the next ServiceExportsSynced.
+func (c *RemoteConnector) onActivated() {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	if err := c.updateConfigsOfManagedCluster(); err != nil {
+		klog.Errorf("[%s] Failed to take over the cluster: %s", ctx.ClusterKey, err)
+		return
+	}
+
+	// the gateway certificate might be issued by the root CA of previous control plane
+	if err := c.syncGatewayCertificate(); err != nil {
+		klog.Errorf("[%s] Failed to sync gateway certificate: %s", ctx.ClusterKey, err)
+	}
+
+	c.broker.Enqueue(event.Message{Kind: event.ClusterTakenOver, NewObj: ctx.ConnectorConfig})
+
+	for clusterKey, healthy := range c.observedClusterHealth() {
+		go c.markClusterHealth(clusterKey, healthy)
+	}
+}
+
+// observeClusterHealth records the health of other clusters even if the control plane is standing by,
+// so that it's applied to the ServiceImports once the cluster is taken over
+func (c *RemoteConnector) observeClusterHealth(clusterKey string, healthy bool) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	c.clusterHealth[clusterKey] = healthy
+}
+
+func (c *RemoteConnector) observedClusterHealth() map[string]bool {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	result := make(map[string]bool, len(c.clusterHealth))
+	for clusterKey, healthy := range c.clusterHealth {
+		result[clusterKey] = healthy
+	}
+
+	return result
+}
+
+// isClaimable returns true if the control plane is allowed to create the Lease of the cluster,
+// a standby control plane never claims a cluster and the cluster managed by others is left as is
+func (c *RemoteConnector) isClaimable() bool {
+	ctx := c.context.(*conn.ConnectorContext)
+	if ctx.ControlPlane.Standby {
+		return false
+	}
+
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	return !mc.IsManaged || mc.Cluster.ControlPlaneUID == "" || mc.Cluster.ControlPlaneUID == ctx.ConnectorConfig.ControlPlaneUID()
+}
+
+// isLeaseExpired returns true if the Lease held by another control plane isn't renewed for its duration,
+// the local time of observing the change is used, so that it's immune to clock skew between clusters
+func (c *RemoteConnector) isLeaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	record := fmt.Sprintf("%s/%s", pointer.StringDeref(lease.Spec.HolderIdentity, ""), lease.ResourceVersion)
+	if record != c.observedRecord {
+		c.observedRecord = record
+		c.observedTime = now
+		return false
+	}
+
+	duration := time.Duration(pointer.Int32Deref(lease.Spec.LeaseDurationSeconds, 0)) * time.Second
+	return now.After(c.observedTime.Add(duration))
+}
+
+func (c *RemoteConnector) setActiveUntil(t time.Time) {
+	var until int64
+	if !t.IsZero() {
+		until = t.UnixNano()
+	}
+	atomic.StoreInt64(&c.activeUntil, until)
+}
+
+// isActive returns true if the control plane holds the Lease of the cluster
+func (c *RemoteConnector) isActive() bool {
+	return time.Now().UnixNano() < atomic.LoadInt64(&c.activeUntil)
+}
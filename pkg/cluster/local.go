@@ -50,6 +50,8 @@ func (c *LocalConnector) Run(stopCh <-chan struct{}) error {
 	go controllers.IngressClassv1.Run(stopCh)
 	go controllers.Ingressv1.Run(stopCh)
 	go controllers.ServiceImport.Run(stopCh)
+	go controllers.ServiceExport.Run(stopCh)
+	go controllers.GlobalTrafficPolicy.Run(stopCh)
 	go controllers.Secret.Run(stopCh)
 
 	// start the informers manually
@@ -74,6 +76,13 @@ func (c *LocalConnector) Run(stopCh <-chan struct{}) error {
 		runtime.HandleError(fmt.Errorf("timed out waiting for ingress class cache to sync"))
 	}
 
+	// GlobalTrafficPolicy must be synced before ServiceImport, as it decides which targets of the ServiceImport are used
+	klog.V(3).Infof("Starting GlobalTrafficPolicy informer ......")
+	go controllers.GlobalTrafficPolicy.Informer.Run(stopCh)
+	if !k8scache.WaitForCacheSync(stopCh, controllers.GlobalTrafficPolicy.HasSynced) {
+		runtime.HandleError(fmt.Errorf("timed out waiting for GlobalTrafficPolicy to sync"))
+	}
+
 	// start the ServiceExport Informer
 	klog.V(3).Infof("Starting ServiceImport informer ......")
 	go controllers.ServiceImport.Informer.Run(stopCh)
@@ -81,6 +90,13 @@ func (c *LocalConnector) Run(stopCh <-chan struct{}) error {
 		runtime.HandleError(fmt.Errorf("timed out waiting for ServiceExport to sync"))
 	}
 
+	// ServiceExports in L4 mode are proxied by ingress-pipy
+	klog.V(3).Infof("Starting ServiceExport informer ......")
+	go controllers.ServiceExport.Informer.Run(stopCh)
+	if !k8scache.WaitForCacheSync(stopCh, controllers.ServiceExport.HasSynced) {
+		runtime.HandleError(fmt.Errorf("timed out waiting for ServiceExport to sync"))
+	}
+
 	// Sleep for a while, so that there's enough time for processing
 	klog.V(5).Infof("Sleep for a while ......")
 	time.Sleep(1 * time.Second)
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/event"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"time"
+)
+
+// pendingImportInterval is the interval of checking whether the namespaces of pending imports exist
+const pendingImportInterval = 30 * time.Second
+
+// importServiceExport imports the accepted ServiceExport and reports the result back to the exporting
+// cluster. If the namespace doesn't exist and it's not allowed to be created, the import is pending
+// until the namespace is created.
+func (c *RemoteConnector) importServiceExport(broker *event.Broker, svcExportEvt *event.ServiceExportEvent) error {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	err := c.upsertServiceImport(svcExportEvt)
+	c.reportImportStatus(broker, svcExportEvt, err)
+	switch {
+	case err == nil:
+		c.forgetPendingImport(svcExportEvt)
+		return nil
+	case err == errNamespaceNotAutoCreated:
+		klog.Warningf("[%s] ServiceExport %s/%s is pending: %s", ctx.ClusterKey, svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name, err)
+		c.addPendingImport(svcExportEvt)
+		return nil
+	default:
+		return err
+	}
+}
+
+// retryPendingImports imports the pending ServiceExports once their namespaces exist, or the
+// namespaces are allowed to be created after the mesh config changes
+func (c *RemoteConnector) retryPendingImports(stopCh <-chan struct{}) {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	ticker := time.NewTicker(pendingImportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !c.isActive() {
+				continue
+			}
+
+			mc := c.clusterCfg.MeshConfig.GetConfig()
+			for _, svcExportEvt := range c.pendingImports() {
+				namespace := c.importNamespace(svcExportEvt.ServiceExport)
+				if !mc.IsNamespaceAutoCreated(namespace) && !c.namespaceExists(namespace) {
+					continue
+				}
+
+				klog.V(5).Infof("[%s] Namespace %q is available, importing pending ServiceExport %s/%s ...", ctx.ClusterKey, namespace, svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name)
+				if err := c.importServiceExport(c.broker, svcExportEvt); err != nil {
+					klog.Errorf("[%s] Failed to import pending ServiceExport %s/%s: %s", ctx.ClusterKey, svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name, err)
+				}
+			}
+		case <-stopCh:
+			klog.Infof("[%s] Stop retrying pending imports.", ctx.ClusterKey)
+			return
+		}
+	}
+}
+
+func (c *RemoteConnector) namespaceExists(namespace string) bool {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	_, err := c.k8sAPI.Client.CoreV1().
+		Namespaces().
+		Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("[%s] Failed to get Namespace %q: %s", ctx.ClusterKey, namespace, err)
+	}
+
+	return err == nil
+}
+
+func (c *RemoteConnector) addPendingImport(svcExportEvt *event.ServiceExportEvent) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	c.pending[pendingImportKey(svcExportEvt)] = svcExportEvt
+}
+
+// forgetPendingImport is called once the ServiceExport is imported, deleted or the cluster is no longer
+// a target of it
+func (c *RemoteConnector) forgetPendingImport(svcExportEvt *event.ServiceExportEvent) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	delete(c.pending, pendingImportKey(svcExportEvt))
+}
+
+// forgetStalePendingImports forgets the pending imports of the exporting cluster whose ServiceExports
+// don't exist any more, they may be deleted while no connector was running
+func (c *RemoteConnector) forgetStalePendingImports(syncEvt *event.ServiceExportSyncEvent) {
+	exported := make(map[string]bool)
+	for _, export := range syncEvt.ServiceExports {
+		exported[fmt.Sprintf("%s/%s/%s", syncEvt.ClusterKey(), export.Namespace, export.Name)] = true
+	}
+
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	for key, svcExportEvt := range c.pending {
+		if svcExportEvt.ClusterKey() == syncEvt.ClusterKey() && !exported[key] {
+			delete(c.pending, key)
+		}
+	}
+}
+
+func (c *RemoteConnector) pendingImports() []*event.ServiceExportEvent {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	result := make([]*event.ServiceExportEvent, 0, len(c.pending))
+	for _, svcExportEvt := range c.pending {
+		result = append(result, svcExportEvt)
+	}
+
+	return result
+}
+
+func pendingImportKey(svcExportEvt *event.ServiceExportEvent) string {
+	return fmt.Sprintf("%s/%s/%s", svcExportEvt.ClusterKey(), svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name)
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/event"
+	"k8s.io/klog/v2"
+	"net"
+	"time"
+)
+
+const (
+	probeInterval    = 15 * time.Second
+	probeTimeout     = 5 * time.Second
+	failureThreshold = 3
+)
+
+// probe checks the API server and gateway of the remote cluster periodically, the result of
+// every probe is published as ClusterProbed event. ClusterUnhealthy is published after the gateway
+// fails failureThreshold consecutive times, and ClusterRecovered once it's reachable again.
+func (c *RemoteConnector) probe(stopCh <-chan struct{}) {
+	ctx := c.context.(*conn.ConnectorContext)
+	connectorCfg := ctx.ConnectorConfig
+
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+
+	healthy := true
+	failures := 0
+	for {
+		select {
+		case <-ticker.C:
+			result := c.probeOnce()
+			c.broker.Enqueue(event.Message{Kind: event.ClusterProbed, NewObj: result})
+
+			if result.Healthy() {
+				failures = 0
+				if !healthy {
+					klog.Infof("[%s] Cluster is recovered", connectorCfg.Key())
+					healthy = true
+					c.broker.Enqueue(event.Message{Kind: event.ClusterRecovered, NewObj: result})
+				}
+				continue
+			}
+
+			failures++
+			klog.Warningf("[%s] Failed to probe cluster(%d/%d), API server: %q, gateway: %q", connectorCfg.Key(), failures, failureThreshold, result.APIServerError, result.GatewayError)
+			if healthy && failures >= failureThreshold {
+				klog.Errorf("[%s] Cluster is unhealthy", connectorCfg.Key())
+				healthy = false
+				c.broker.Enqueue(event.Message{Kind: event.ClusterUnhealthy, NewObj: result})
+			}
+		case <-stopCh:
+			klog.Infof("[%s] Stop probing cluster.", connectorCfg.Key())
+			return
+		}
+	}
+}
+
+func (c *RemoteConnector) probeOnce() *event.ClusterProbeEvent {
+	ctx := c.context.(*conn.ConnectorContext)
+	connectorCfg := ctx.ConnectorConfig
+
+	result := &event.ClusterProbeEvent{
+		Geo:                connectorCfg,
+		ProbeTime:          time.Now(),
+		APIServerReachable: true,
+		GatewayReachable:   true,
+		Active:             c.isActive(),
+	}
+
+	if err := c.probeAPIServer(); err != nil {
+		result.APIServerReachable = false
+		result.APIServerError = err.Error()
+	}
+
+	if err := probeGateway(connectorCfg.GatewayHost(), connectorCfg.GatewayPort()); err != nil {
+		result.GatewayReachable = false
+		result.GatewayError = err.Error()
+	}
+
+	return result
+}
+
+func (c *RemoteConnector) probeAPIServer() error {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	return c.k8sAPI.Client.Discovery().RESTClient().
+		Get().
+		AbsPath("/readyz").
+		Do(ctx).
+		Error()
+}
+
+func probeGateway(host string, port int32) error {
+	if host == "" {
+		return fmt.Errorf("gateway host is empty")
+	}
+
+	gw, err := net.DialTimeout("tcp", net.JoinHostPort(host, fmt.Sprintf("%d", port)), probeTimeout)
+	if err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
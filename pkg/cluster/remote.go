@@ -21,28 +21,39 @@ import (
 	"fmt"
 	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
 	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/cache"
 	"github.com/flomesh-io/ErieCanal/pkg/cache/controller"
 	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
 	"github.com/flomesh-io/ErieCanal/pkg/event"
 	retry "github.com/sethvargo/go-retry"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metautil "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	k8scache "k8s.io/client-go/tools/cache"
+	k8sretry "k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"net"
-	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"sync/atomic"
 	"time"
 )
 
+var errNamespaceNotAutoCreated = fmt.Errorf("namespace doesn't exist and it's not allowed to be created, the import is pending until it's created")
+
 func (c *RemoteConnector) Run(stopCh <-chan struct{}) error {
 	ctx := c.context.(*conn.ConnectorContext)
 	connectorCfg := ctx.ConnectorConfig
 	errCh := make(chan error)
 
+	// the control plane Lease is acquired before joining, it fences control planes
+	c.renewLease()
+
 	err := c.updateConfigsOfManagedCluster()
 	if err != nil {
 		return err
@@ -71,13 +82,31 @@ func (c *RemoteConnector) Run(stopCh <-chan struct{}) error {
 
 	// register event handler
 	mc := c.clusterCfg.MeshConfig.GetConfig()
-	if mc.IsManaged {
+	if mc.IsManaged || ctx.ControlPlane.Standby {
 		go c.processEvent(c.broker, stopCh)
 	}
 
 	// start the cache runner
 	go c.cache.SyncLoop(stopCh)
 
+	// start probing the health of cluster
+	go c.probe(stopCh)
+
+	// renew the control plane Lease periodically
+	go c.heartbeat(stopCh)
+
+	// keep the gateway IPs up to date if the gateway host is a DNS name
+	go c.resolveGateway(stopCh)
+
+	// issue and renew the gateway certificate if gateway TLS is enabled
+	go c.syncGatewayCertificatePeriodically(stopCh)
+
+	// issue and renew the intermediate CA of the cluster
+	go c.syncClusterCAPeriodically(stopCh)
+
+	// import the ServiceExports pending on their namespaces
+	go c.retryPendingImports(stopCh)
+
 	return <-errCh
 }
 
@@ -90,13 +119,22 @@ func (c *RemoteConnector) updateConfigsOfManagedCluster() error {
 		mcClient := c.clusterCfg.MeshConfig
 		mc := mcClient.GetConfig()
 
-		if mc.IsManaged && mc.Cluster.ControlPlaneUID != "" {
-			if mc.Cluster.ControlPlaneUID != connectorCfg.ControlPlaneUID() {
-				return fmt.Errorf("cluster %s is already managed, cannot join the MultiCluster", connectorCfg.Key())
-			} else {
-				klog.Infof("[%s] Rejoining ClusterSet ...", connectorCfg.Key())
+		if !c.isActive() {
+			if ctx.ControlPlane.Standby {
+				klog.Infof("[%s] Standing by, cluster is managed by control plane %q", connectorCfg.Key(), mc.Cluster.ControlPlaneUID)
+				return nil
 			}
+
+			return fmt.Errorf("cluster %s is already managed or the control plane Lease isn't acquired, cannot join the MultiCluster", connectorCfg.Key())
+		}
+
+		if mc.IsManaged && mc.Cluster.ControlPlaneUID == connectorCfg.ControlPlaneUID() {
+			klog.Infof("[%s] Rejoining ClusterSet ...", connectorCfg.Key())
 		} else {
+			if mc.IsManaged && mc.Cluster.ControlPlaneUID != "" {
+				klog.Infof("[%s] Taking over cluster from control plane %q ...", connectorCfg.Key(), mc.Cluster.ControlPlaneUID)
+			}
+
 			mc.IsManaged = true
 			mc.Cluster.Region = connectorCfg.Region()
 			mc.Cluster.Zone = connectorCfg.Zone()
@@ -109,11 +147,69 @@ func (c *RemoteConnector) updateConfigsOfManagedCluster() error {
 			}
 
 		}
+
+		// it's retried periodically, joining the cluster doesn't depend on it
+		if err := c.syncClusterCA(); err != nil {
+			klog.Errorf("[%s] Failed to sync intermediate CA: %s", connectorCfg.Key(), err)
+		}
 	}
 
 	return nil
 }
 
+// LeaveClusterSet resets the cluster fields of MeshConfig in the managed cluster to the defaults,
+// so that it's able to join another ClusterSet
+func (c *RemoteConnector) LeaveClusterSet() error {
+	ctx := c.context.(*conn.ConnectorContext)
+	connectorCfg := ctx.ConnectorConfig
+
+	mcClient := c.clusterCfg.MeshConfig
+	mc := mcClient.GetConfig()
+	if !mc.IsManaged {
+		return nil
+	}
+
+	if mc.Cluster.ControlPlaneUID != "" && mc.Cluster.ControlPlaneUID != connectorCfg.ControlPlaneUID() {
+		klog.Warningf("[%s] Cluster is managed by another control plane %s, leave it as is", connectorCfg.Key(), mc.Cluster.ControlPlaneUID)
+		return nil
+	}
+
+	klog.Infof("[%s] Leaving ClusterSet ...", connectorCfg.Key())
+	mc.IsManaged = false
+	mc.Cluster.Region = "default"
+	mc.Cluster.Zone = "default"
+	mc.Cluster.Group = "default"
+	mc.Cluster.Name = "local"
+	mc.Cluster.ControlPlaneUID = ""
+
+	if _, err := mcClient.UpdateConfig(mc); err != nil {
+		return err
+	}
+
+	// release the cluster, so that the standby control plane doesn't take it over
+	atomic.StoreInt32(&c.left, 1)
+	if err := c.k8sAPI.Client.CoordinationV1().
+		Leases(config.GetErieCanalNamespace()).
+		Delete(context.TODO(), commons.ControlPlaneLeaseName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	c.setActiveUntil(time.Time{})
+
+	if err := c.k8sAPI.Client.CoreV1().
+		Secrets(config.GetErieCanalNamespace()).
+		Delete(context.TODO(), commons.GatewayTLSSecretName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if err := c.deleteClusterCA(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// processEvent federates services into the cluster, the operations are dropped while this control plane
+// doesn't hold the Lease of the cluster, they're replayed by re-exporting services once it takes over
 func (c *RemoteConnector) processEvent(broker *event.Broker, stopCh <-chan struct{}) {
 	ctx := c.context.(*conn.ConnectorContext)
 	connectorCfg := ctx.ConnectorConfig
@@ -127,6 +223,16 @@ func (c *RemoteConnector) processEvent(broker *event.Broker, stopCh <-chan struc
 	defer broker.Unsub(msgBus, svcExportAcceptedCh)
 	svcExportRejectedCh := msgBus.Sub(string(event.ServiceExportRejected))
 	defer broker.Unsub(msgBus, svcExportRejectedCh)
+	svcExportsSyncedCh := msgBus.Sub(string(event.ServiceExportsSynced))
+	defer broker.Unsub(msgBus, svcExportsSyncedCh)
+	svcImportReportedCh := msgBus.Sub(string(event.ServiceImportReported))
+	defer broker.Unsub(msgBus, svcImportReportedCh)
+	clusterUnhealthyCh := msgBus.Sub(string(event.ClusterUnhealthy))
+	defer broker.Unsub(msgBus, clusterUnhealthyCh)
+	clusterRecoveredCh := msgBus.Sub(string(event.ClusterRecovered))
+	defer broker.Unsub(msgBus, clusterRecoveredCh)
+	clusterTakenOverCh := msgBus.Sub(string(event.ClusterTakenOver))
+	defer broker.Unsub(msgBus, clusterTakenOverCh)
 
 	for {
 		// FIXME: refine it later
@@ -152,11 +258,16 @@ func (c *RemoteConnector) processEvent(broker *event.Broker, stopCh <-chan struc
 
 			go func() {
 				if err := retry.Fibonacci(c.context, 1*time.Second, func(ctx context.Context) error {
+					if !c.isActive() {
+						return nil
+					}
+
 					if err := c.deleteServiceImport(svcExportEvt); err != nil {
 						// This marks the error as retryable
 						return retry.RetryableError(err)
 					}
 
+					c.forgetPendingImport(svcExportEvt)
 					return nil
 				}); err != nil {
 					klog.Errorf("[%s] Failed to delete ServiceImport %s/%s", connectorCfg.Key(), svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name)
@@ -181,9 +292,37 @@ func (c *RemoteConnector) processEvent(broker *event.Broker, stopCh <-chan struc
 				continue
 			}
 
+			if !svcExportEvt.ServiceExport.IsTargetCluster(connectorCfg.Key()) {
+				// The cluster may be dropped from the target clusters, remove the imported endpoints if any
+				klog.V(5).Infof("[%s] Cluster is not a target of ServiceExport %s/%s", connectorCfg.Key(), svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name)
+				go func() {
+					if err := retry.Fibonacci(c.context, 1*time.Second, func(ctx context.Context) error {
+						if !c.isActive() {
+							return nil
+						}
+
+						if err := c.deleteServiceImport(svcExportEvt); err != nil {
+							// This marks the error as retryable
+							return retry.RetryableError(err)
+						}
+
+						c.forgetPendingImport(svcExportEvt)
+						c.withdrawImportStatus(broker, svcExportEvt)
+						return nil
+					}); err != nil {
+						klog.Errorf("[%s] Failed to delete ServiceImport %s/%s", connectorCfg.Key(), svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name)
+					}
+				}()
+				continue
+			}
+
 			go func() {
 				if err := retry.Fibonacci(c.context, 1*time.Second, func(ctx context.Context) error {
-					if err := c.upsertServiceImport(svcExportEvt); err != nil {
+					if !c.isActive() {
+						return nil
+					}
+
+					if err := c.importServiceExport(broker, svcExportEvt); err != nil {
 						// This marks the error as retryable
 						return retry.RetryableError(err)
 					}
@@ -214,67 +353,150 @@ func (c *RemoteConnector) processEvent(broker *event.Broker, stopCh <-chan struc
 
 			go func() {
 				if err := retry.Fibonacci(c.context, 1*time.Second, func(ctx context.Context) error {
+					if !c.isActive() {
+						return nil
+					}
+
 					if err := c.rejectServiceExport(svcExportEvt); err != nil {
 						// This marks the error as retryable
 						return retry.RetryableError(err)
 					}
 
+					c.reportImportStatus(broker, svcExportEvt, fmt.Errorf("ServiceExport is rejected, %s", svcExportEvt.Error))
 					return nil
 				}); err != nil {
 					klog.Errorf("[%s] Failed to handle Reject Event of ServiceExport %s/%s: %s", connectorCfg.Key(), svcExportEvt.ServiceExport.Namespace, svcExportEvt.ServiceExport.Name, err)
 				}
 			}()
-		case <-stopCh:
-			klog.Infof("[%s] Received stop signal.", connectorCfg.Key())
-			return
-		}
-	}
-}
+		case msg, ok := <-svcExportsSyncedCh:
+			if !ok {
+				klog.Warningf("[%s] Channel closed for ServiceExport", connectorCfg.Key())
+				continue
+			}
+			klog.V(5).Infof("[%s] received event ServiceExportsSynced %#v", connectorCfg.Key(), msg)
 
-func (c *RemoteConnector) ServiceImportExists(svcExp *svcexpv1alpha1.ServiceExport) bool {
-	ctx := c.context.(*conn.ConnectorContext)
+			e, ok := msg.(event.Message)
+			if !ok {
+				klog.Errorf("[%s] Received unexpected message %T on channel, expected Message", connectorCfg.Key(), e)
+				continue
+			}
 
-	if _, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
-		ServiceImports(svcExp.Namespace).
-		Get(context.TODO(), svcExp.Name, metav1.GetOptions{}); err != nil {
-		if errors.IsNotFound(err) {
-			klog.V(5).Infof("[%s] ServiceImport %s/%s doesn't exist", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
-			return false
-		}
+			syncEvt, ok := e.NewObj.(*event.ServiceExportSyncEvent)
+			if !ok {
+				klog.Errorf("[%s] Received unexpected object %T, expected *event.ServiceExportSyncEvent", connectorCfg.Key(), syncEvt)
+				continue
+			}
 
-		klog.Errorf("[%s] Failed to get of ServiceImport %s/%s: %s", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, err)
-		return true
-	}
+			if syncEvt.ClusterKey() == connectorCfg.Key() {
+				continue
+			}
 
-	klog.V(5).Infof("[%s] ServiceImport %s/%s already exists", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
-	return true
-}
+			go func() {
+				if err := retry.Fibonacci(c.context, 1*time.Second, func(ctx context.Context) error {
+					if !c.isActive() {
+						return nil
+					}
 
-func (c *RemoteConnector) ValidateServiceExport(svcExp *svcexpv1alpha1.ServiceExport, service *corev1.Service) error {
-	ctx := c.context.(*conn.ConnectorContext)
-	clusterKey := ctx.ClusterKey
-	localSvc, err := c.k8sAPI.Client.CoreV1().
-		Services(svcExp.Namespace).
-		Get(context.TODO(), svcExp.Name, metav1.GetOptions{})
+					if err := c.pruneServiceImports(syncEvt); err != nil {
+						// This marks the error as retryable
+						return retry.RetryableError(err)
+					}
 
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// If not found this svc in the cluster, then there' no conflict possibility
-			klog.V(5).Infof("[%s] Service %s/%s doesn't exist, no conflict", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
-			return nil
-		}
-		return fmt.Errorf("[%s] Failed get Service %s/%s: %s", clusterKey, svcExp.Namespace, svcExp.Name, err)
-	}
+					c.forgetStalePendingImports(syncEvt)
+					return nil
+				}); err != nil {
+					klog.Errorf("[%s] Failed to prune ServiceImports of cluster %s: %s", connectorCfg.Key(), syncEvt.ClusterKey(), err)
+				}
+			}()
+		case msg, ok := <-svcImportReportedCh:
+			if !ok {
+				klog.Warningf("[%s] Channel closed for ServiceImport", connectorCfg.Key())
+				continue
+			}
+			klog.V(5).Infof("[%s] received event ServiceImportReported %#v", connectorCfg.Key(), msg)
 
-	if service.Spec.Type != localSvc.Spec.Type {
-		return fmt.Errorf("[%s] service type doesn't match: %s vs %s", clusterKey, service.Spec.Type, localSvc.Spec.Type)
-	}
+			e, ok := msg.(event.Message)
+			if !ok {
+				klog.Errorf("[%s] Received unexpected message %T on channel, expected Message", connectorCfg.Key(), e)
+				continue
+			}
 
-	if !reflect.DeepEqual(service.Spec.Ports, localSvc.Spec.Ports) {
-		return fmt.Errorf("[%s] spec.ports conflict, please check service spec", clusterKey)
-	}
+			reportEvt, ok := e.NewObj.(*event.ServiceImportReportEvent)
+			if !ok {
+				klog.Errorf("[%s] Received unexpected object %T, expected *event.ServiceImportReportEvent", connectorCfg.Key(), reportEvt)
+				continue
+			}
 
-	return nil
+			// only the connector of exporting cluster updates the status
+			if reportEvt.ClusterKey() != connectorCfg.Key() {
+				continue
+			}
+
+			go func() {
+				if err := retry.Fibonacci(c.context, 1*time.Second, func(ctx context.Context) error {
+					if !c.isActive() {
+						return nil
+					}
+
+					if err := c.updateImportStatus(reportEvt); err != nil {
+						// This marks the error as retryable
+						return retry.RetryableError(err)
+					}
+
+					return nil
+				}); err != nil {
+					klog.Errorf("[%s] Failed to update import status of ServiceExport %s/%s: %s", connectorCfg.Key(), reportEvt.ServiceExport.Namespace, reportEvt.ServiceExport.Name, err)
+				}
+			}()
+		case msg, ok := <-clusterUnhealthyCh:
+			if !ok {
+				klog.Warningf("[%s] Channel closed for Cluster", connectorCfg.Key())
+				continue
+			}
+			klog.V(5).Infof("[%s] received event ClusterUnhealthy %#v", connectorCfg.Key(), msg)
+
+			probeEvt, ok := probeEventOf(msg)
+			if !ok || probeEvt.ClusterKey() == connectorCfg.Key() {
+				continue
+			}
+
+			// stop routing to the unhealthy cluster, the endpoints are kept so that it's back once recovered
+			c.observeClusterHealth(probeEvt.ClusterKey(), false)
+			go c.markClusterHealth(probeEvt.ClusterKey(), false)
+		case msg, ok := <-clusterRecoveredCh:
+			if !ok {
+				klog.Warningf("[%s] Channel closed for Cluster", connectorCfg.Key())
+				continue
+			}
+			klog.V(5).Infof("[%s] received event ClusterRecovered %#v", connectorCfg.Key(), msg)
+
+			probeEvt, ok := probeEventOf(msg)
+			if !ok {
+				continue
+			}
+
+			if probeEvt.ClusterKey() != connectorCfg.Key() {
+				c.observeClusterHealth(probeEvt.ClusterKey(), true)
+				go c.markClusterHealth(probeEvt.ClusterKey(), true)
+				continue
+			}
+
+			// the recovered cluster exports its services again, in case any change is missed
+			c.reExportServices()
+		case msg, ok := <-clusterTakenOverCh:
+			if !ok {
+				klog.Warningf("[%s] Channel closed for Cluster", connectorCfg.Key())
+				continue
+			}
+			klog.V(5).Infof("[%s] received event ClusterTakenOver %#v", connectorCfg.Key(), msg)
+
+			// the services are exported again, so that they're imported by the cluster taken over
+			c.reExportServices()
+		case <-stopCh:
+			klog.Infof("[%s] Received stop signal.", connectorCfg.Key())
+			return
+		}
+	}
 }
 
 func (c *RemoteConnector) upsertServiceImport(export *event.ServiceExportEvent) error {
@@ -282,136 +504,178 @@ func (c *RemoteConnector) upsertServiceImport(export *event.ServiceExportEvent)
 	exportClusterKey := export.ClusterKey()
 	svcExp := export.ServiceExport
 	if exportClusterKey == ctx.ClusterKey {
-		klog.Warningf("[%s] ServiceExport %s/%s is ignored as it occurs in same cluster", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
-		return nil
+		// the export is accepted, but still conflicts with others if there's an error
+		return c.updateConflictCondition(export)
 	}
 
-	imp, err := c.getOrCreateServiceImport(export)
+	tls, err := c.gatewayTLS(export)
 	if err != nil {
 		return err
 	}
-	klog.V(5).Infof("[%s] Created/Found ServiceImport %s/%s: %#v", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, imp)
+
+	imp, err := c.getOrCreateServiceImport(export, tls)
+	if err != nil {
+		return err
+	}
+	klog.V(5).Infof("[%s] Created/Found ServiceImport %s/%s: %#v", ctx.ClusterKey, imp.Namespace, imp.Name, imp)
 
 	//ports := make([]svcimpv1alpha1.ServicePort, 0)
 	for idx, p := range imp.Spec.Ports {
 		klog.V(5).Infof("[%s] processing port %d, len(endpoints)=%d", ctx.ClusterKey, p.Port, len(p.Endpoints))
+		matched := false
 		endpoints := make([]svcimpv1alpha1.Endpoint, 0)
-		if len(p.Endpoints) == 0 {
-			for _, r := range svcExp.Spec.Rules {
-				if r.PortNumber == p.Port {
-					ep := newEndpoint(export, r, export.Geo.GatewayHost(), export.Geo.GatewayIP(), export.Geo.GatewayPort())
-					klog.V(5).Infof("[%s] processing port %d, ep=%#v", ctx.ClusterKey, p.Port, ep)
-					endpoints = append(endpoints, ep)
-				}
+		for _, r := range svcExp.Spec.Rules {
+			if matchesPort(svcExp, r, p.Port, p.Protocol) {
+				// insert/update, endpoints of the exporting cluster are replaced as a whole,
+				// as Pods of a headless service may come and go
+				eps := newEndpoints(export, r, tls)
+				klog.V(5).Infof("[%s] processing port %d, eps=%#v", ctx.ClusterKey, p.Port, eps)
+				endpoints = append(endpoints, eps...)
+				matched = true
 			}
-		} else {
-			epMap := make(map[string]svcimpv1alpha1.Endpoint)
-			for _, r := range svcExp.Spec.Rules {
-				if r.PortNumber == p.Port {
-					// copy
-					for _, ep := range p.Endpoints {
-						klog.V(5).Infof("[%s] processing port %d, existing ep=%#v", ctx.ClusterKey, p.Port, ep)
-						epMap[ep.ClusterKey] = *ep.DeepCopy()
-					}
+		}
 
-					// insert/update
-					epMap[exportClusterKey] = newEndpoint(export, r, export.Geo.GatewayHost(), export.Geo.GatewayIP(), export.Geo.GatewayPort())
-				}
-			}
+		if !matched {
+			continue
+		}
 
-			for _, ep := range epMap {
-				klog.V(5).Infof("[%s] port %d, endpoint entry=%#v", ctx.ClusterKey, p.Port, ep)
-				endpoints = append(endpoints, ep)
+		// copy endpoints of other clusters
+		for _, ep := range p.Endpoints {
+			if ep.ClusterKey != exportClusterKey {
+				klog.V(5).Infof("[%s] processing port %d, existing ep=%#v", ctx.ClusterKey, p.Port, ep)
+				endpoints = append(endpoints, *ep.DeepCopy())
 			}
 		}
 
+		// keep the order stable, avoid unnecessary updates of the ServiceImport
+		sort.SliceStable(endpoints, func(i, j int) bool {
+			if endpoints[i].ClusterKey != endpoints[j].ClusterKey {
+				return endpoints[i].ClusterKey < endpoints[j].ClusterKey
+			}
+			return endpoints[i].Hostname < endpoints[j].Hostname
+		})
+
 		imp.Spec.Ports[idx].Endpoints = endpoints
 		klog.V(5).Infof("[%s] len of endpoints of port %d is %d", ctx.ClusterKey, p.Port, len(imp.Spec.Ports[idx].Endpoints))
 	}
+	imp.Spec.Type = serviceImportType(export.Service)
 	imp.Spec.ServiceAccountName = svcExp.Spec.ServiceAccountName
 	klog.V(5).Infof("[%s] After merging, ServiceImport %s/%s: %#v", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, imp)
 
-	klog.V(5).Infof("[%s] updating ServiceImport %s/%s ...", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
+	klog.V(5).Infof("[%s] updating ServiceImport %s/%s ...", ctx.ClusterKey, imp.Namespace, imp.Name)
 	if _, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
-		ServiceImports(svcExp.Namespace).
+		ServiceImports(imp.Namespace).
 		Update(context.TODO(), imp, metav1.UpdateOptions{}); err != nil {
-		klog.Errorf("[%s] Failed to update ServiceImport %s/%s: %s", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, err)
+		klog.Errorf("[%s] Failed to update ServiceImport %s/%s: %s", ctx.ClusterKey, imp.Namespace, imp.Name, err)
 		return err
 	}
 
 	return nil
 }
 
-func (c *RemoteConnector) getOrCreateServiceImport(export *event.ServiceExportEvent) (*svcimpv1alpha1.ServiceImport, error) {
+func (c *RemoteConnector) getOrCreateServiceImport(export *event.ServiceExportEvent, tls *svcimpv1alpha1.TargetTLS) (*svcimpv1alpha1.ServiceImport, error) {
 	ctx := c.context.(*conn.ConnectorContext)
 	svcExp := export.ServiceExport
+	namespace := c.importNamespace(svcExp)
 
-	ns := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: svcExp.Namespace,
-		},
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: "v1",
-			Kind:       "Namespace",
-		},
-	}
-	if _, err := c.k8sAPI.Client.CoreV1().
-		Namespaces().
-		Create(context.TODO(), ns, metav1.CreateOptions{}); err != nil {
-		if errors.IsAlreadyExists(err) {
-			klog.V(5).Infof("[%s] Namespace %q exists", ctx.ClusterKey, svcExp.Namespace)
-		} else {
-			klog.Errorf("[%s] Failed to create Namespace %q: %s", ctx.ClusterKey, svcExp.Namespace, err)
-			return nil, err
-		}
+	if err := c.ensureImportNamespace(namespace); err != nil {
+		return nil, err
 	}
 
-	imp := c.newServiceImport(export)
+	imp := c.newServiceImport(export, tls)
 	if imp == nil {
-		return nil, fmt.Errorf("[%s] Failed to new instance of ServiceImport %s/%s", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
+		return nil, fmt.Errorf("[%s] Failed to new instance of ServiceImport %s/%s", ctx.ClusterKey, namespace, svcExp.Name)
 	}
 
 	imp, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
-		ServiceImports(svcExp.Namespace).
+		ServiceImports(namespace).
 		Create(context.TODO(), imp, metav1.CreateOptions{})
 	if err != nil {
 		if errors.IsAlreadyExists(err) {
-			klog.V(5).Infof("[%s] ServiceImport %s/%s already exists, getting it ...", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
+			klog.V(5).Infof("[%s] ServiceImport %s/%s already exists, getting it ...", ctx.ClusterKey, namespace, svcExp.Name)
 			imp, err = c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
-				ServiceImports(svcExp.Namespace).
+				ServiceImports(namespace).
 				Get(context.TODO(), svcExp.Name, metav1.GetOptions{})
 			if err != nil {
-				klog.Errorf("[%s] Failed to get ServiceImport %s/%s: %s", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, err)
+				klog.Errorf("[%s] Failed to get ServiceImport %s/%s: %s", ctx.ClusterKey, namespace, svcExp.Name, err)
 				return nil, err
 			}
 
 			return imp, nil
 		}
 
-		klog.Errorf("[%s] Failed to create ServiceImport %s/%s: %s", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, err)
+		klog.Errorf("[%s] Failed to create ServiceImport %s/%s: %s", ctx.ClusterKey, namespace, svcExp.Name, err)
 		return nil, err
 	}
 
-	klog.V(5).Infof("[%s] ServiceImport %s/%s is created successfully", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
+	klog.V(5).Infof("[%s] ServiceImport %s/%s is created successfully", ctx.ClusterKey, namespace, svcExp.Name)
 	return imp, nil
 }
 
-func (c *RemoteConnector) newServiceImport(export *event.ServiceExportEvent) *svcimpv1alpha1.ServiceImport {
+// importNamespace is the namespace of this cluster which the exported service is imported into, it's
+// mapped by the control plane so that all clusters import the service into the same namespace which
+// the conflicts are resolved by
+func (c *RemoteConnector) importNamespace(svcExp *svcexpv1alpha1.ServiceExport) string {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	return ctx.ClusterSetConfig.GetConfig().ImportNamespace(svcExp.Namespace)
+}
+
+// ensureImportNamespace creates the namespace if it doesn't exist and it's allowed by the
+// namespace sameness policy of this cluster
+func (c *RemoteConnector) ensureImportNamespace(namespace string) error {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	_, err := c.k8sAPI.Client.CoreV1().
+		Namespaces().
+		Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err == nil {
+		klog.V(5).Infof("[%s] Namespace %q exists", ctx.ClusterKey, namespace)
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		klog.Errorf("[%s] Failed to get Namespace %q: %s", ctx.ClusterKey, namespace, err)
+		return err
+	}
+
+	mc := c.clusterCfg.MeshConfig.GetConfig()
+	if !mc.IsNamespaceAutoCreated(namespace) {
+		return errNamespaceNotAutoCreated
+	}
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+		},
+	}
+	if _, err := c.k8sAPI.Client.CoreV1().
+		Namespaces().
+		Create(context.TODO(), ns, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		klog.Errorf("[%s] Failed to create Namespace %q: %s", ctx.ClusterKey, namespace, err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *RemoteConnector) newServiceImport(export *event.ServiceExportEvent, tls *svcimpv1alpha1.TargetTLS) *svcimpv1alpha1.ServiceImport {
 	svcExp := export.ServiceExport
 	service := export.Service
 
 	ports := make([]svcimpv1alpha1.ServicePort, 0)
 	for _, r := range svcExp.Spec.Rules {
 		for _, p := range service.Spec.Ports {
-			if r.PortNumber == p.Port {
+			if matchesPort(svcExp, r, p.Port, p.Protocol) {
 				ports = append(ports, svcimpv1alpha1.ServicePort{
 					Name:        p.Name,
 					Port:        p.Port,
 					Protocol:    p.Protocol,
 					AppProtocol: p.AppProtocol,
-					Endpoints: []svcimpv1alpha1.Endpoint{
-						newEndpoint(export, r, export.Geo.GatewayHost(), export.Geo.GatewayIP(), export.Geo.GatewayPort()),
-					},
+					Endpoints:   newEndpoints(export, r, tls),
 				})
 			}
 		}
@@ -420,33 +684,92 @@ func (c *RemoteConnector) newServiceImport(export *event.ServiceExportEvent) *sv
 	return &svcimpv1alpha1.ServiceImport{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      svcExp.Name,
-			Namespace: svcExp.Namespace,
+			Namespace: c.importNamespace(svcExp),
 		},
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "flomesh.io/v1alpha1",
 			Kind:       "ServiceImport",
 		},
 		Spec: svcimpv1alpha1.ServiceImportSpec{
-			Type:               svcimpv1alpha1.ClusterSetIP, // ONLY set the value, there's no any logic to handle the type yet
+			Type:               serviceImportType(service),
 			Ports:              ports,
 			ServiceAccountName: svcExp.Spec.ServiceAccountName,
 		},
 	}
 }
 
-func newEndpoint(export *event.ServiceExportEvent, r svcexpv1alpha1.ServiceExportRule, host string, ip net.IP, port int32) svcimpv1alpha1.Endpoint {
-	return svcimpv1alpha1.Endpoint{
+// serviceImportType is Headless if the exported Service is headless, otherwise ClusterSetIP
+func serviceImportType(service *corev1.Service) svcimpv1alpha1.ServiceImportType {
+	if service != nil && service.Spec.ClusterIP == corev1.ClusterIPNone {
+		return svcimpv1alpha1.Headless
+	}
+
+	return svcimpv1alpha1.ClusterSetIP
+}
+
+// matchesPort returns true if the rule exports the service port, protocol is ONLY matched in L4 mode
+// as HTTP rules are always TCP
+func matchesPort(export *svcexpv1alpha1.ServiceExport, r svcexpv1alpha1.ServiceExportRule, port int32, protocol corev1.Protocol) bool {
+	if r.PortNumber != port {
+		return false
+	}
+
+	return !export.IsL4() || r.GetProtocol() == protocol
+}
+
+// newEndpoints returns one endpoint per Pod hostname for a headless Service, as each Pod is routed
+// individually by the gateway of exporting cluster, otherwise a single endpoint of the gateway.
+// In L4 mode, it's a single endpoint of the dedicated gateway port, or none if the port is not allocated yet
+func newEndpoints(export *event.ServiceExportEvent, r svcexpv1alpha1.ServiceExportRule, tls *svcimpv1alpha1.TargetTLS) []svcimpv1alpha1.Endpoint {
+	if export.ServiceExport.IsL4() {
+		port := export.ServiceExport.GatewayPort(r.PortNumber, r.GetProtocol())
+		if port == 0 {
+			return []svcimpv1alpha1.Endpoint{}
+		}
+
+		ep := newEndpoint(export, r, export.Geo.GatewayHost(), export.Geo.GatewayIPs(), port)
+		ep.Target.Path = ""
+		return []svcimpv1alpha1.Endpoint{ep}
+	}
+
+	ep := newEndpoint(export, r, export.Geo.GatewayHost(), export.Geo.GatewayIPs(), export.Geo.GatewayPort())
+	ep.Target.TLS = tls
+	if serviceImportType(export.Service) != svcimpv1alpha1.Headless || len(export.ServiceExport.Status.Hostnames) == 0 {
+		return []svcimpv1alpha1.Endpoint{ep}
+	}
+
+	endpoints := make([]svcimpv1alpha1.Endpoint, 0)
+	for _, hostname := range export.ServiceExport.Status.Hostnames {
+		podEp := *ep.DeepCopy()
+		podEp.Hostname = hostname
+		endpoints = append(endpoints, podEp)
+	}
+
+	return endpoints
+}
+
+func newEndpoint(export *event.ServiceExportEvent, r svcexpv1alpha1.ServiceExportRule, host string, ips []net.IP, port int32) svcimpv1alpha1.Endpoint {
+	ep := svcimpv1alpha1.Endpoint{
 		ClusterKey: export.ClusterKey(),
 		//Targets: []string{
 		//	fmt.Sprintf("%s%s", export.Geo.Gateway(), r.Path),
 		//},
 		Target: svcimpv1alpha1.Target{
 			Host: host,
-			IP:   ip.String(),
+			IP:   ips[0].String(),
 			Port: port,
 			Path: r.Path,
 		},
 	}
+
+	// the gateway of a dual-stack cluster is reachable by addresses of both IP families
+	if len(ips) > 1 {
+		for _, ip := range ips {
+			ep.Target.IPs = append(ep.Target.IPs, ip.String())
+		}
+	}
+
+	return ep
 }
 
 func (c *RemoteConnector) deleteServiceImport(export *event.ServiceExportEvent) error {
@@ -458,8 +781,9 @@ func (c *RemoteConnector) deleteServiceImport(export *event.ServiceExportEvent)
 		return nil
 	}
 
+	namespace := c.importNamespace(svcExp)
 	imp, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
-		ServiceImports(svcExp.Namespace).
+		ServiceImports(namespace).
 		Get(context.TODO(), svcExp.Name, metav1.GetOptions{})
 
 	if err != nil {
@@ -472,7 +796,12 @@ func (c *RemoteConnector) deleteServiceImport(export *event.ServiceExportEvent)
 	}
 
 	if imp.DeletionTimestamp != nil {
-		klog.Warningf("[%s] ServiceImport %s/%s is being deleted, ignore it", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
+		klog.Warningf("[%s] ServiceImport %s/%s is being deleted, ignore it", ctx.ClusterKey, namespace, svcExp.Name)
+		return nil
+	}
+
+	if !hasEndpointsOfCluster(imp, exportClusterKey) {
+		klog.V(5).Infof("[%s] ServiceImport %s/%s has no endpoint of cluster %s, ignore it", ctx.ClusterKey, namespace, svcExp.Name, exportClusterKey)
 		return nil
 	}
 
@@ -501,57 +830,481 @@ func (c *RemoteConnector) deleteServiceImport(export *event.ServiceExportEvent)
 	if len(ports) > 0 {
 		imp.Spec.Ports = ports
 		if _, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
-			ServiceImports(svcExp.Namespace).
+			ServiceImports(namespace).
 			Update(context.TODO(), imp, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("[%s] Failed to update ServiceImport %s/%s: %s", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, err)
+			klog.Errorf("[%s] Failed to update ServiceImport %s/%s: %s", ctx.ClusterKey, namespace, svcExp.Name, err)
 			return err
 		}
-		klog.V(5).Infof("[%s] ServiceImport %s/%s is updated successfully", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
+		klog.V(5).Infof("[%s] ServiceImport %s/%s is updated successfully", ctx.ClusterKey, namespace, svcExp.Name)
 	} else {
 		if err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
-			ServiceImports(svcExp.Namespace).
+			ServiceImports(namespace).
 			Delete(context.TODO(), svcExp.Name, metav1.DeleteOptions{}); err != nil {
-			klog.Errorf("[%s] Failed to delete ServiceImport %s/%s: %s", ctx.ClusterKey, svcExp.Namespace, svcExp.Name, err)
+			klog.Errorf("[%s] Failed to delete ServiceImport %s/%s: %s", ctx.ClusterKey, namespace, svcExp.Name, err)
+			return err
+		}
+		klog.V(5).Infof("[%s] ServiceImport %s/%s is deleted successfully", ctx.ClusterKey, namespace, svcExp.Name)
+	}
+
+	return nil
+}
+
+func probeEventOf(msg interface{}) (*event.ClusterProbeEvent, bool) {
+	e, ok := msg.(event.Message)
+	if !ok {
+		klog.Errorf("Received unexpected message %T on channel, expected Message", msg)
+		return nil, false
+	}
+
+	probeEvt, ok := e.NewObj.(*event.ClusterProbeEvent)
+	if !ok {
+		klog.Errorf("Received unexpected object %T, expected *event.ClusterProbeEvent", e.NewObj)
+		return nil, false
+	}
+
+	return probeEvt, true
+}
+
+// RemoveEndpointsOfCluster removes the endpoints of the cluster which leaves ClusterSet from all ServiceImports,
+// the ServiceImport is deleted if there's no endpoint left
+func (c *RemoteConnector) RemoveEndpointsOfCluster(clusterKey string) error {
+	if !c.isActive() {
+		return nil
+	}
+
+	imports, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
+		ServiceImports(corev1.NamespaceAll).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range imports.Items {
+		imp := &imports.Items[i]
+		if imp.DeletionTimestamp != nil || !hasEndpointsOfCluster(imp, clusterKey) {
+			continue
+		}
+
+		if err := c.removeEndpointsFromServiceImport(imp, clusterKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markClusterHealth marks the cluster unhealthy or healthy in status of all ServiceImports which have
+// endpoints of it, it's retried until succeeded or the connector stops
+func (c *RemoteConnector) markClusterHealth(clusterKey string, healthy bool) {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	if err := retry.Fibonacci(c.context, 1*time.Second, func(_ context.Context) error {
+		if !c.isActive() {
+			return nil
+		}
+
+		if err := c.setClusterHealth(clusterKey, healthy); err != nil {
+			return retry.RetryableError(err)
+		}
+
+		return nil
+	}); err != nil {
+		klog.Errorf("[%s] Failed to mark health of cluster %s: %s", ctx.ClusterKey, clusterKey, err)
+	}
+}
+
+func (c *RemoteConnector) setClusterHealth(clusterKey string, healthy bool) error {
+	imports, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
+		ServiceImports(corev1.NamespaceAll).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range imports.Items {
+		imp := &imports.Items[i]
+		if imp.DeletionTimestamp != nil || !hasEndpointsOfCluster(imp, clusterKey) {
+			continue
+		}
+
+		if err := k8sretry.RetryOnConflict(k8sretry.DefaultRetry, func() error {
+			return c.setClusterHealthOfServiceImport(imp.Namespace, imp.Name, clusterKey, healthy)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *RemoteConnector) setClusterHealthOfServiceImport(namespace, name, clusterKey string, healthy bool) error {
+	ctx := c.context.(*conn.ConnectorContext)
+	serviceImports := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().ServiceImports(namespace)
+
+	imp, err := serviceImports.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	idx := -1
+	for i, cs := range imp.Status.Clusters {
+		if cs.Cluster == clusterKey {
+			idx = i
+			break
+		}
+	}
+
+	switch {
+	case idx >= 0 && imp.Status.Clusters[idx].Unhealthy == !healthy:
+		return nil
+	case idx >= 0:
+		imp.Status.Clusters[idx].Unhealthy = !healthy
+	case healthy:
+		return nil
+	default:
+		// the status of cluster is filled in by the ServiceImport controller later
+		imp.Status.Clusters = append(imp.Status.Clusters, svcimpv1alpha1.ClusterStatus{Cluster: clusterKey, Unhealthy: true})
+	}
+
+	if _, err := serviceImports.UpdateStatus(context.TODO(), imp, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	klog.V(2).Infof("[%s] Cluster %s is marked healthy=%t in ServiceImport %s/%s", ctx.ClusterKey, clusterKey, healthy, namespace, name)
+	return nil
+}
+
+// pruneServiceImports removes the endpoints of the exporting cluster from ServiceImports
+// which have no corresponding ServiceExport in it any more
+func (c *RemoteConnector) pruneServiceImports(syncEvt *event.ServiceExportSyncEvent) error {
+	ctx := c.context.(*conn.ConnectorContext)
+	clusterKey := syncEvt.ClusterKey()
+
+	exported := make(map[string]bool)
+	for _, export := range syncEvt.ServiceExports {
+		exported[fmt.Sprintf("%s/%s", c.importNamespace(export), export.Name)] = true
+	}
+
+	imports, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
+		ServiceImports(corev1.NamespaceAll).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range imports.Items {
+		imp := &imports.Items[i]
+		if imp.DeletionTimestamp != nil || !hasEndpointsOfCluster(imp, clusterKey) {
+			continue
+		}
+
+		if exported[fmt.Sprintf("%s/%s", imp.Namespace, imp.Name)] {
+			continue
+		}
+
+		klog.Infof("[%s] ServiceExport of ServiceImport %s/%s doesn't exist in cluster %s, pruning ...", ctx.ClusterKey, imp.Namespace, imp.Name, clusterKey)
+		if err := c.removeEndpointsFromServiceImport(imp, clusterKey); err != nil {
 			return err
 		}
-		klog.V(5).Infof("[%s] ServiceImport %s/%s is deleted successfully", ctx.ClusterKey, svcExp.Namespace, svcExp.Name)
 	}
 
 	return nil
 }
 
+// removeEndpointsFromServiceImport removes the endpoints of the cluster from the ServiceImport,
+// it's deleted if there's no endpoint left
+func (c *RemoteConnector) removeEndpointsFromServiceImport(imp *svcimpv1alpha1.ServiceImport, clusterKey string) error {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	ports := make([]svcimpv1alpha1.ServicePort, 0)
+	for _, p := range imp.Spec.Ports {
+		endpoints := make([]svcimpv1alpha1.Endpoint, 0)
+		for _, ep := range p.Endpoints {
+			if ep.ClusterKey != clusterKey {
+				endpoints = append(endpoints, *ep.DeepCopy())
+			}
+		}
+
+		if len(endpoints) > 0 {
+			p.Endpoints = endpoints
+			ports = append(ports, *p.DeepCopy())
+		}
+	}
+
+	if len(ports) > 0 {
+		imp.Spec.Ports = ports
+		if _, err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
+			ServiceImports(imp.Namespace).
+			Update(context.TODO(), imp, metav1.UpdateOptions{}); err != nil {
+			klog.Errorf("[%s] Failed to update ServiceImport %s/%s: %s", ctx.ClusterKey, imp.Namespace, imp.Name, err)
+			return err
+		}
+		klog.V(5).Infof("[%s] Endpoints of cluster %s are removed from ServiceImport %s/%s", ctx.ClusterKey, clusterKey, imp.Namespace, imp.Name)
+	} else {
+		if err := c.k8sAPI.FlomeshClient.ServiceimportV1alpha1().
+			ServiceImports(imp.Namespace).
+			Delete(context.TODO(), imp.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("[%s] Failed to delete ServiceImport %s/%s: %s", ctx.ClusterKey, imp.Namespace, imp.Name, err)
+			return err
+		}
+		klog.V(5).Infof("[%s] ServiceImport %s/%s is deleted as no endpoint of cluster %s is left", ctx.ClusterKey, imp.Namespace, imp.Name, clusterKey)
+	}
+
+	return nil
+}
+
+// reExportServices publishes all ServiceExports of the cluster again
+func (c *RemoteConnector) reExportServices() {
+	ctx := c.context.(*conn.ConnectorContext)
+	controllers := c.cache.GetControllers().(*controller.RemoteControllers)
+
+	exports, err := controllers.ServiceExport.Lister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("[%s] Failed to list ServiceExports: %s", ctx.ClusterKey, err)
+		return
+	}
+
+	remoteCache := c.cache.(*cache.RemoteCache)
+	for _, export := range exports {
+		remoteCache.OnUpdate(nil, export)
+	}
+}
+
+func hasEndpointsOfCluster(imp *svcimpv1alpha1.ServiceImport, clusterKey string) bool {
+	for _, p := range imp.Spec.Ports {
+		for _, ep := range p.Endpoints {
+			if ep.ClusterKey == clusterKey {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (c *RemoteConnector) rejectServiceExport(svcExportEvt *event.ServiceExportEvent) error {
 	ctx := c.context.(*conn.ConnectorContext)
+
+	if ctx.ClusterKey == svcExportEvt.ClusterKey() {
+		return c.updateConflictCondition(svcExportEvt)
+	}
+
+	// the export might be accepted before, stop importing it
+	return c.deleteServiceImport(svcExportEvt)
+}
+
+// updateConflictCondition sets the Conflict condition of ServiceExport in the exporting cluster,
+// it's true if the event carries an error
+func (c *RemoteConnector) updateConflictCondition(svcExportEvt *event.ServiceExportEvent) error {
+	ctx := c.context.(*conn.ConnectorContext)
 	export := svcExportEvt.ServiceExport
 	//reason := svcExportEvt.Data["reason"]
 	reason := svcExportEvt.Error
 
-	if ctx.ClusterKey == svcExportEvt.ClusterKey() {
-		exp, err := c.k8sAPI.FlomeshClient.ServiceexportV1alpha1().
-			ServiceExports(export.Namespace).
-			Get(context.TODO(), export.Name, metav1.GetOptions{})
-		if err != nil {
-			klog.Errorf("[%s] Failed to get ServiceExport %s/%s: %s", ctx.ClusterKey, export.Namespace, export.Name, err)
-			return err
+	exp, err := c.k8sAPI.FlomeshClient.ServiceexportV1alpha1().
+		ServiceExports(export.Namespace).
+		Get(context.TODO(), export.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
 		}
+		klog.Errorf("[%s] Failed to get ServiceExport %s/%s: %s", ctx.ClusterKey, export.Namespace, export.Name, err)
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:               string(svcexpv1alpha1.ServiceExportConflict),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: exp.Generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             "NoConflict",
+		Message:            fmt.Sprintf("ServiceExport %s/%s has no conflict", exp.Namespace, exp.Name),
+	}
+	if reason != "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Conflict"
+		condition.Message = fmt.Sprintf("ServiceExport %s/%s conflicts, %s", exp.Namespace, exp.Name, reason)
+	}
 
+	existing := metautil.FindStatusCondition(exp.Status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message {
+		return nil
+	}
+
+	if reason != "" {
 		c.cache.GetRecorder().Eventf(exp, nil, corev1.EventTypeWarning, "Rejected", "ServiceExport %s/%s is invalid, %s", exp.Namespace, exp.Name, reason)
+	} else if existing != nil && existing.Status == metav1.ConditionTrue {
+		c.cache.GetRecorder().Eventf(exp, nil, corev1.EventTypeNormal, "ConflictResolved", "Conflict of ServiceExport %s/%s is resolved", exp.Namespace, exp.Name)
+	}
 
-		metautil.SetStatusCondition(&exp.Status.Conditions, metav1.Condition{
-			Type:               string(svcexpv1alpha1.ServiceExportConflict),
-			Status:             metav1.ConditionTrue,
-			ObservedGeneration: exp.Generation,
-			LastTransitionTime: metav1.Time{Time: time.Now()},
-			Reason:             "Conflict",
-			Message:            fmt.Sprintf("ServiceExport %s/%s conflicts, %s", exp.Namespace, exp.Name, reason),
-		})
+	metautil.SetStatusCondition(&exp.Status.Conditions, condition)
+	if _, err := c.k8sAPI.FlomeshClient.ServiceexportV1alpha1().
+		ServiceExports(export.Namespace).
+		UpdateStatus(context.TODO(), exp, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("[%s] Failed to update status of ServiceExport %s/%s: %s", ctx.ClusterKey, exp.Namespace, exp.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+// importStatusRefreshInterval is how often an unchanged import status is refreshed in the ServiceExport
+const importStatusRefreshInterval = 5 * time.Minute
+
+// reportImportStatus reports the result of importing the ServiceExport to the exporting cluster
+func (c *RemoteConnector) reportImportStatus(broker *event.Broker, svcExportEvt *event.ServiceExportEvent, err error) {
+	ctx := c.context.(*conn.ConnectorContext)
+	if svcExportEvt.ClusterKey() == ctx.ClusterKey {
+		return
+	}
+
+	reportEvt := &event.ServiceImportReportEvent{
+		Geo:              svcExportEvt.Geo,
+		ServiceExport:    svcExportEvt.ServiceExport,
+		ImportClusterKey: ctx.ClusterKey,
+		Imported:         err == nil,
+		ReportTime:       time.Now(),
+	}
+	if err != nil {
+		reportEvt.Error = err.Error()
+	}
+
+	broker.Enqueue(event.Message{Kind: event.ServiceImportReported, NewObj: reportEvt})
+}
+
+// withdrawImportStatus reports the cluster is no longer a target of the ServiceExport
+func (c *RemoteConnector) withdrawImportStatus(broker *event.Broker, svcExportEvt *event.ServiceExportEvent) {
+	ctx := c.context.(*conn.ConnectorContext)
+	if svcExportEvt.ClusterKey() == ctx.ClusterKey {
+		return
+	}
+
+	broker.Enqueue(event.Message{
+		Kind: event.ServiceImportReported,
+		NewObj: &event.ServiceImportReportEvent{
+			Geo:              svcExportEvt.Geo,
+			ServiceExport:    svcExportEvt.ServiceExport,
+			ImportClusterKey: ctx.ClusterKey,
+			Withdrawn:        true,
+			ReportTime:       time.Now(),
+		},
+	})
+}
+
+// updateImportStatus merges the reported import status into the ServiceExport of the exporting cluster
+func (c *RemoteConnector) updateImportStatus(reportEvt *event.ServiceImportReportEvent) error {
+	ctx := c.context.(*conn.ConnectorContext)
+	export := reportEvt.ServiceExport
+
+	exp, err := c.k8sAPI.FlomeshClient.ServiceexportV1alpha1().
+		ServiceExports(export.Namespace).
+		Get(context.TODO(), export.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("[%s] Failed to get ServiceExport %s/%s: %s", ctx.ClusterKey, export.Namespace, export.Name, err)
+		return err
+	}
+
+	// the report is stale if the ServiceExport has been re-created
+	if exp.UID != export.UID {
+		return nil
+	}
+
+	status := svcexpv1alpha1.ServiceExportClusterStatus{
+		ClusterKey:   reportEvt.ImportClusterKey,
+		Imported:     reportEvt.Imported,
+		LastError:    reportEvt.Error,
+		ObservedTime: metav1.Time{Time: reportEvt.ReportTime},
+	}
+
+	found := false
+	clusters := make([]svcexpv1alpha1.ServiceExportClusterStatus, 0)
+	for _, cs := range exp.Status.Clusters {
+		if cs.ClusterKey != status.ClusterKey {
+			clusters = append(clusters, cs)
+			continue
+		}
+
+		found = true
+		if reportEvt.Withdrawn {
+			continue
+		}
+
+		if cs.Imported == status.Imported &&
+			cs.LastError == status.LastError &&
+			status.ObservedTime.Sub(cs.ObservedTime.Time) < importStatusRefreshInterval {
+			return nil
+		}
+		clusters = append(clusters, status)
+	}
+
+	if !found {
+		if reportEvt.Withdrawn {
+			return nil
+		}
+		clusters = append(clusters, status)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].ClusterKey < clusters[j].ClusterKey
+	})
+
+	if !reportEvt.Imported && !reportEvt.Withdrawn {
+		c.cache.GetRecorder().Eventf(exp, nil, corev1.EventTypeWarning, "ImportFailed", "ServiceExport %s/%s is not imported by cluster %s, %s", exp.Namespace, exp.Name, status.ClusterKey, status.LastError)
+	}
+
+	exp.Status.Clusters = clusters
+	if _, err := c.k8sAPI.FlomeshClient.ServiceexportV1alpha1().
+		ServiceExports(exp.Namespace).
+		UpdateStatus(context.TODO(), exp, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("[%s] Failed to update status of ServiceExport %s/%s: %s", ctx.ClusterKey, exp.Namespace, exp.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+// ForgetImportsOfCluster removes the import status of the cluster from all ServiceExports
+func (c *RemoteConnector) ForgetImportsOfCluster(clusterKey string) error {
+	ctx := c.context.(*conn.ConnectorContext)
+	if !c.isActive() {
+		return nil
+	}
+
+	exports, err := c.k8sAPI.FlomeshClient.ServiceexportV1alpha1().
+		ServiceExports(corev1.NamespaceAll).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range exports.Items {
+		exp := &exports.Items[i]
+
+		clusters := make([]svcexpv1alpha1.ServiceExportClusterStatus, 0)
+		for _, cs := range exp.Status.Clusters {
+			if cs.ClusterKey != clusterKey {
+				clusters = append(clusters, cs)
+			}
+		}
+
+		if len(clusters) == len(exp.Status.Clusters) {
+			continue
+		}
 
+		exp.Status.Clusters = clusters
 		if _, err := c.k8sAPI.FlomeshClient.ServiceexportV1alpha1().
-			ServiceExports(export.Namespace).
+			ServiceExports(exp.Namespace).
 			UpdateStatus(context.TODO(), exp, metav1.UpdateOptions{}); err != nil {
 			klog.Errorf("[%s] Failed to update status of ServiceExport %s/%s: %s", ctx.ClusterKey, exp.Namespace, exp.Name, err)
 			return err
 		}
+		klog.V(5).Infof("[%s] Import status of cluster %s is removed from ServiceExport %s/%s", ctx.ClusterKey, clusterKey, exp.Namespace, exp.Name)
 	}
 
 	return nil
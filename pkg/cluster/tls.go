@@ -0,0 +1,182 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/certificate/utils"
+	conn "github.com/flomesh-io/ErieCanal/pkg/cluster/context"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"github.com/flomesh-io/ErieCanal/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"strings"
+	"time"
+)
+
+const (
+	gatewayCertValidityPeriod = 365 * 24 * time.Hour
+	// gatewayCertRenewBefore is how long before expiration the gateway certificate is renewed
+	gatewayCertRenewBefore  = 30 * 24 * time.Hour
+	gatewayCertSyncInterval = 1 * time.Hour
+)
+
+// syncGatewayCertificatePeriodically keeps the gateway certificate of the cluster valid, it's checked
+// hourly and renewed before expiration
+func (c *RemoteConnector) syncGatewayCertificatePeriodically(stopCh <-chan struct{}) {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	wait.Until(func() {
+		if err := c.syncGatewayCertificate(); err != nil {
+			klog.Errorf("[%s] Failed to sync gateway certificate: %s", ctx.ClusterKey, err)
+		}
+	}, gatewayCertSyncInterval, stopCh)
+}
+
+// syncGatewayCertificate issues the gateway certificate to the cluster from the root CA of control plane,
+// it's stored in a Secret of the cluster. The Secret is removed if gateway TLS is disabled.
+func (c *RemoteConnector) syncGatewayCertificate() error {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	if !c.isActive() {
+		return nil
+	}
+
+	secrets := c.k8sAPI.Client.CoreV1().Secrets(config.GetErieCanalNamespace())
+	secret, err := secrets.Get(context.TODO(), commons.GatewayTLSSecretName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !ctx.GatewayTLS.Enabled {
+		if !exists {
+			return nil
+		}
+
+		klog.Infof("[%s] Gateway TLS is disabled, deleting gateway certificate ...", ctx.ClusterKey)
+		if err := secrets.Delete(context.TODO(), commons.GatewayTLSSecretName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if exists && !c.shouldRenewGatewayCertificate(secret) {
+		return nil
+	}
+
+	serverName := gatewayServerName(ctx.ClusterKey)
+	cert, err := c.certMgr.IssueCertificate(serverName, gatewayCertValidityPeriod, []string{serverName})
+	if err != nil {
+		return err
+	}
+
+	data := map[string][]byte{
+		commons.RootCACertName:    cert.CA,
+		commons.TLSCertName:       cert.CrtPEM,
+		commons.TLSPrivateKeyName: cert.KeyPEM,
+	}
+
+	if exists {
+		secret.Data = data
+		if _, err := secrets.Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	} else {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      commons.GatewayTLSSecretName,
+				Namespace: config.GetErieCanalNamespace(),
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+		if _, err := secrets.Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	klog.Infof("[%s] Issued gateway certificate for %s, it expires at %s", ctx.ClusterKey, serverName, cert.Expiration)
+	return nil
+}
+
+// shouldRenewGatewayCertificate returns true if the certificate is about to expire, or it's not issued by
+// the root CA of this control plane, e.g. the cluster is taken over from another control plane
+func (c *RemoteConnector) shouldRenewGatewayCertificate(secret *corev1.Secret) bool {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	root, err := c.certMgr.GetRootCertificate()
+	if err != nil {
+		klog.Errorf("[%s] Failed to get root certificate: %s", ctx.ClusterKey, err)
+		return false
+	}
+	if !bytes.Equal(secret.Data[commons.RootCACertName], root.CA) {
+		return true
+	}
+
+	cert, err := utils.ConvertPEMCertToX509(secret.Data[commons.TLSCertName])
+	if err != nil {
+		klog.Warningf("[%s] Invalid gateway certificate, renew it: %s", ctx.ClusterKey, err)
+		return true
+	}
+
+	return time.Now().Add(gatewayCertRenewBefore).After(cert.NotAfter) ||
+		!strings.EqualFold(cert.Subject.CommonName, gatewayServerName(ctx.ClusterKey))
+}
+
+// gatewayTLS returns how the gateway of exporting cluster is verified, it's nil if gateway TLS is
+// disabled or the export is in L4 mode, as L4 traffic is proxied as is
+func (c *RemoteConnector) gatewayTLS(export *event.ServiceExportEvent) (*svcimpv1alpha1.TargetTLS, error) {
+	ctx := c.context.(*conn.ConnectorContext)
+
+	if !ctx.GatewayTLS.Enabled || export.ServiceExport.IsL4() {
+		return nil, nil
+	}
+
+	root, err := c.certMgr.GetRootCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get root certificate: %s", err)
+	}
+
+	return &svcimpv1alpha1.TargetTLS{
+		SNI: gatewayServerName(export.ClusterKey()),
+		CA:  string(root.CA),
+	}, nil
+}
+
+// gatewayServerName is the server name of the gateway certificate of a cluster, the segments of cluster key
+// are reversed, e.g. cluster1.default.default.default.gateway.clusterset.local
+func gatewayServerName(clusterKey string) string {
+	return fmt.Sprintf("%s.gateway.%s", reversedClusterKey(clusterKey), commons.ClusterSetDomain)
+}
+
+// reversedClusterKey joins the segments of cluster key in reverse order by dot, just like a domain name
+func reversedClusterKey(clusterKey string) string {
+	segments := strings.Split(strings.ToLower(clusterKey), "/")
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+
+	return strings.Join(segments, ".")
+}
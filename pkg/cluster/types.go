@@ -19,9 +19,12 @@ package cluster
 import (
 	"context"
 	"github.com/flomesh-io/ErieCanal/pkg/cache"
+	"github.com/flomesh-io/ErieCanal/pkg/certificate"
 	"github.com/flomesh-io/ErieCanal/pkg/config"
 	"github.com/flomesh-io/ErieCanal/pkg/event"
 	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"sync"
+	"time"
 )
 
 type Connector interface {
@@ -42,4 +45,21 @@ type RemoteConnector struct {
 	cache      cache.Cache
 	clusterCfg *config.Store
 	broker     *event.Broker
+	certMgr    certificate.Manager
+
+	// left is set once the cluster leaves the ClusterSet, the Lease is never acquired again
+	left int32
+	// activeUntil is the UnixNano time until which the control plane holds the Lease of the cluster
+	activeUntil int64
+	// observedRecord and observedTime track the Lease held by another control plane,
+	// it's expired if the record doesn't change for the lease duration
+	observedRecord string
+	observedTime   time.Time
+
+	// pending holds the accepted ServiceExports whose namespaces don't exist and aren't allowed to be created
+	pendingMu sync.Mutex
+	pending   map[string]*event.ServiceExportEvent
+	// clusterHealth is the last observed health of other clusters, it's re-applied once taking over the cluster
+	healthMu      sync.Mutex
+	clusterHealth map[string]bool
 }
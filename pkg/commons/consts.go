@@ -35,6 +35,8 @@ const (
 	DefaultCALocality             = "Dalian"
 	DefaultCAOrganization         = "flomesh.io"
 	ManagerDeploymentName         = "erie-canal-manager"
+	ManagerServiceName            = "erie-canal-manager"
+	ClusterEgressServiceName      = "erie-canal-cluster-egress"
 	MeshConfigName                = "erie-canal-mesh-config"
 	MeshConfigJsonName            = "mesh_config.json"
 	DefaultPipyRepoPath           = "/repo"
@@ -130,8 +132,23 @@ const (
 	//MultiClustersExportedName      = MultiClustersPrefix + "/export-name"
 
 	// ServiceNameLabel is used to indicate the name of multi-cluster service
-	// that an EndpointSlice belongs to.
-	//ServiceNameLabel = MultiClustersPrefix + "/service-name"
+	// that a derived Service/Endpoints belongs to.
+	ServiceNameLabel = MultiClustersPrefix + "/service-name"
+	// DerivedServicePrefix is the name prefix of the Service derived from a ServiceImport
+	DerivedServicePrefix = "derived-"
+	// ClusterSetDomain is the DNS zone of multi-cluster services, as defined by MCS API
+	ClusterSetDomain = "clusterset.local"
+	// DefaultKubeconfigSecretKey is the key of kubeconfig in the Secret referenced by a Cluster
+	DefaultKubeconfigSecretKey = "kubeconfig"
+	// ControlPlaneLeaseName is the Lease in member clusters which fences control planes,
+	// only the holder federates services of the member
+	ControlPlaneLeaseName = "control-plane.flomesh.io"
+	// GatewayTLSSecretName is the Secret in member clusters which holds the gateway certificate
+	// issued by control plane, ingress-pipy presents it to other clusters and vice versa
+	GatewayTLSSecretName = "erie-canal-gateway-tls"
+	// ClusterCASecretName is the Secret in member clusters which holds the intermediate CA issued by
+	// control plane, certificates of the member are signed by it and ca.crt is the trust bundle of ClusterSet
+	ClusterCASecretName = "erie-canal-cluster-ca"
 
 	ClusterTpl = "{{ .Region }}/{{ .Zone }}/{{ .Group }}/{{ .Cluster }}"
 )
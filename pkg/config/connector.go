@@ -21,9 +21,9 @@ import (
 	"github.com/flomesh-io/ErieCanal/pkg/commons"
 	"github.com/flomesh-io/ErieCanal/pkg/util"
 	"k8s.io/apimachinery/pkg/util/validation"
-	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	"net"
+	"sync"
 )
 
 type ConnectorConfig struct {
@@ -34,7 +34,8 @@ type ConnectorConfig struct {
 	inCluster       bool
 	key             string
 	gatewayHost     string
-	gatewayIP       net.IP
+	gatewayIPs      []net.IP
+	gatewayMu       sync.RWMutex
 	gatewayPort     int32
 	controlPlaneUID string
 }
@@ -68,42 +69,14 @@ func NewConnectorConfig(
 	}
 
 	if !inCluster {
-		isDNSName := false
-		if ipErrs := validation.IsValidIPv4Address(field.NewPath(""), gatewayHost); len(ipErrs) > 0 {
-			// Not IPv4 address
-			klog.Warningf("%q is NOT a valid IPv4 address: %v", gatewayHost, ipErrs)
-			if dnsErrs := validation.IsDNS1123Subdomain(gatewayHost); len(dnsErrs) > 0 {
-				// Not valid DNS domain name
-				return nil, fmt.Errorf("invalid DNS name or IP %q: %v", gatewayHost, dnsErrs)
-			} else {
-				// is DNS name
-				isDNSName = true
-			}
-		}
-
-		var gwIPv4 net.IP
-		if isDNSName {
-			ipAddr, err := net.ResolveIPAddr("ip4", gatewayHost)
-			if err != nil {
-				return nil, fmt.Errorf("%q cannot be resolved to IP, %s", gatewayHost, err)
-			}
-			klog.Infof("%q is resolved to IP: %s", gatewayHost, ipAddr.IP)
-			gwIPv4 = ipAddr.IP.To4()
-		} else {
-			gwIPv4 = net.ParseIP(gatewayHost).To4()
-		}
-
-		if gwIPv4 == nil {
-			return nil, fmt.Errorf("%q cannot be resolved to a IPv4 address", gatewayHost)
-		}
-
-		if gwIPv4 != nil && (gwIPv4.IsLoopback() || gwIPv4.IsUnspecified()) {
-			return nil, fmt.Errorf("gateway Host %s is resolved to Loopback IP or Unspecified", gatewayHost)
+		gwIPs, err := ResolveGatewayIPs(gatewayHost)
+		if err != nil {
+			return nil, err
 		}
 
 		c.gatewayHost = gatewayHost
 		c.gatewayPort = gatewayPort
-		c.gatewayIP = gwIPv4
+		c.gatewayIPs = gwIPs
 	}
 
 	return c, nil
@@ -140,11 +113,42 @@ func (c *ConnectorConfig) GatewayHost() string {
 	return c.gatewayHost
 }
 
+// GatewayIP returns the primary gateway IP, IPv4 takes precedence in a dual-stack cluster
 func (c *ConnectorConfig) GatewayIP() net.IP {
 	if c.inCluster {
 		return net.IPv4zero
 	}
-	return c.gatewayIP
+	c.gatewayMu.RLock()
+	defer c.gatewayMu.RUnlock()
+	return c.gatewayIPs[0]
+}
+
+// GatewayIPs returns the gateway IPs of all IP families, the primary one comes first
+func (c *ConnectorConfig) GatewayIPs() []net.IP {
+	if c.inCluster {
+		return []net.IP{net.IPv4zero}
+	}
+	c.gatewayMu.RLock()
+	defer c.gatewayMu.RUnlock()
+	return c.gatewayIPs
+}
+
+// IsGatewayDNSName returns true if the gateway host is a DNS name, its IPs may change over time
+func (c *ConnectorConfig) IsGatewayDNSName() bool {
+	return !c.inCluster && net.ParseIP(c.gatewayHost) == nil
+}
+
+// SetGatewayIPs replaces the gateway IPs with the re-resolved ones, it returns true if they're changed
+func (c *ConnectorConfig) SetGatewayIPs(ips []net.IP) bool {
+	c.gatewayMu.Lock()
+	defer c.gatewayMu.Unlock()
+
+	if len(ips) == 0 || equalIPs(c.gatewayIPs, ips) {
+		return false
+	}
+
+	c.gatewayIPs = ips
+	return true
 }
 
 func (c *ConnectorConfig) GatewayPort() int32 {
@@ -157,3 +161,65 @@ func (c *ConnectorConfig) GatewayPort() int32 {
 func (c *ConnectorConfig) ControlPlaneUID() string {
 	return c.controlPlaneUID
 }
+
+// ResolveGatewayIPs returns the gateway IPs of the host, which is either an IPv4/IPv6 address or a DNS name.
+// A DNS name may be resolved to addresses of both IP families, the first of each family is kept and IPv4
+// comes first.
+func ResolveGatewayIPs(host string) ([]net.IP, error) {
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		if dnsErrs := validation.IsDNS1123Subdomain(host); len(dnsErrs) > 0 {
+			// Neither IP nor valid DNS domain name
+			return nil, fmt.Errorf("invalid DNS name or IP %q: %v", host, dnsErrs)
+		}
+
+		resolved, err := net.LookupIP(host)
+		if err != nil {
+			return nil, fmt.Errorf("%q cannot be resolved to IP, %s", host, err)
+		}
+		klog.V(5).Infof("%q is resolved to IPs: %v", host, resolved)
+		ips = resolved
+	}
+
+	var ipv4, ipv6 net.IP
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsUnspecified() {
+			return nil, fmt.Errorf("gateway Host %s is resolved to Loopback IP or Unspecified", host)
+		}
+
+		switch {
+		case ip.To4() != nil && ipv4 == nil:
+			ipv4 = ip.To4()
+		case ip.To4() == nil && ipv6 == nil:
+			ipv6 = ip
+		}
+	}
+
+	result := make([]net.IP, 0, 2)
+	for _, ip := range []net.IP{ipv4, ipv6} {
+		if ip != nil {
+			result = append(result, ip)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%q cannot be resolved to an IP address", host)
+	}
+
+	return result, nil
+}
+
+func equalIPs(a, b []net.IP) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/flomesh-io/ErieCanal/pkg/commons"
 	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"github.com/flomesh-io/ErieCanal/pkg/util"
 	"github.com/go-playground/validator/v10"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -32,6 +33,7 @@ import (
 	v1 "k8s.io/client-go/listers/core/v1"
 	k8scache "k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	"path"
 	"time"
 )
 
@@ -39,6 +41,10 @@ var (
 	validate = validator.New()
 )
 
+func init() {
+	validate.RegisterStructValidation(validateControlPlane, ClusterSetControlPlane{})
+}
+
 type MeshConfig struct {
 	IsManaged   bool        `json:"isManaged"`
 	Repo        Repo        `json:"repo"`
@@ -49,6 +55,7 @@ type MeshConfig struct {
 	Certificate Certificate `json:"certificate"`
 	Cluster     Cluster     `json:"cluster"`
 	ServiceLB   ServiceLB   `json:"serviceLB"`
+	ClusterSet  ClusterSet  `json:"clusterSet"`
 }
 
 type Repo struct {
@@ -71,6 +78,7 @@ type Ingress struct {
 	Namespaced bool `json:"namespaced"`
 	HTTP       HTTP `json:"http"`
 	TLS        TLS  `json:"tls"`
+	L4         L4   `json:"l4"`
 }
 
 type HTTP struct {
@@ -94,6 +102,14 @@ type SSLPassthrough struct {
 	UpstreamPort int32 `json:"upstreamPort" validate:"gte=1,lte=65535"`
 }
 
+type L4 struct {
+	// Enabled allows exporting services in L4 mode, a dedicated port in range [MinPort, MaxPort]
+	// is allocated for each exported port, the range must be exposed by the ingress Service
+	Enabled bool  `json:"enabled"`
+	MinPort int32 `json:"minPort" validate:"gte=0,lte=65535"`
+	MaxPort int32 `json:"maxPort" validate:"gte=0,lte=65535,gtefield=MinPort"`
+}
+
 type GatewayApi struct {
 	Enabled bool `json:"enabled"`
 }
@@ -111,6 +127,136 @@ type ServiceLB struct {
 	Enabled bool `json:"enabled"`
 }
 
+type ClusterSet struct {
+	// VIPs of ClusterSetIP ServiceImports are allocated from this CIDR, VIP allocation is disabled if it's empty.
+	// It must be an IPv4 CIDR which doesn't overlap with the Pod/Service CIDRs of any cluster in the ClusterSet
+	VIPCIDR string        `json:"vipCIDR" validate:"omitempty,cidrv4"`
+	DNS     ClusterSetDNS `json:"dns"`
+	// MCSAPI mirrors the ServiceExports/ServiceImports of upstream multicluster.x-k8s.io group
+	MCSAPI ClusterSetMCSAPI `json:"mcsAPI"`
+	// KubeconfigSecretOnly rejects new Clusters which embed the raw kubeconfig in spec,
+	// kubeconfigSecretRef must be used instead
+	KubeconfigSecretOnly bool `json:"kubeconfigSecretOnly"`
+	// Namespaces is the namespace sameness policy of exports and imports
+	Namespaces ClusterSetNamespaces `json:"namespaces"`
+	// ControlPlane is the failover settings of control plane
+	ControlPlane ClusterSetControlPlane `json:"controlPlane"`
+	// GatewayResolver re-resolves the gateway hosts of clusters which are DNS names
+	GatewayResolver ClusterSetGatewayResolver `json:"gatewayResolver"`
+	// GatewayTLS secures the cross-cluster traffic by mTLS between gateways of clusters
+	GatewayTLS ClusterSetGatewayTLS `json:"gatewayTLS"`
+	// Egress proxies the traffic of imported services to the gateways of other clusters by ingress-pipy
+	Egress ClusterSetEgress `json:"egress"`
+	// OutlierDetection ejects the exporting clusters whose gateways keep failing from imported services
+	OutlierDetection ClusterSetOutlierDetection `json:"outlierDetection"`
+}
+
+type ClusterSetDNS struct {
+	// Enabled starts a DNS server in manager which resolves <svc>.<ns>.svc.clusterset.local,
+	// cluster DNS is expected to forward the clusterset.local zone to it
+	Enabled bool `json:"enabled"`
+	// Port is the UDP/TCP port of the DNS server, changing it requires restarting manager
+	Port int32 `json:"port" validate:"gte=0,lte=65535"`
+}
+
+type ClusterSetNamespaces struct {
+	// Exports lists the namespaces whose services are allowed to be exported, '*' in a pattern matches
+	// any sequence of characters, e.g. team-*. Empty allows all namespaces
+	Exports []string `json:"exports"`
+	// AutoCreate lists the namespaces which are created on import if they don't exist, the patterns are
+	// matched against the namespace after mapping. Empty allows all namespaces
+	AutoCreate []string `json:"autoCreate"`
+	// Mapping remaps the namespace of exporting cluster to the namespace which the service is imported into,
+	// e.g. team-a: team-a-prod. Only the mapping of control plane is used, it applies to all member clusters
+	Mapping map[string]string `json:"mapping"`
+}
+
+// ClusterSetControlPlane fences control planes by a Lease in each member cluster, only the holder of
+// the Lease federates services of the member. Changing it takes effect once the connectors restart
+type ClusterSetControlPlane struct {
+	// Standby never claims a member cluster which isn't managed yet, it takes over the member once
+	// the active control plane misses heartbeats for FailoverTimeout
+	Standby bool `json:"standby"`
+	// HeartbeatPeriod is the interval in seconds of renewing the Lease in member clusters
+	HeartbeatPeriod int32 `json:"heartbeatPeriod" validate:"omitempty,gte=1"`
+	// FailoverTimeout is the duration in seconds of the Lease, the member is taken over by another
+	// control plane if the Lease isn't renewed in time. It must be at least twice the heartbeat interval,
+	// or the Lease expires between heartbeats
+	FailoverTimeout int32 `json:"failoverTimeout" validate:"omitempty,gte=1"`
+}
+
+// minLeaseHeartbeats is the least number of heartbeats the control plane Lease lasts
+const minLeaseHeartbeats = 2
+
+func validateControlPlane(sl validator.StructLevel) {
+	c := sl.Current().Interface().(ClusterSetControlPlane)
+	if c.FailoverTimeout > 0 && time.Duration(c.FailoverTimeout)*time.Second < minLeaseHeartbeats*c.HeartbeatInterval() {
+		sl.ReportError(c.FailoverTimeout, "FailoverTimeout", "failoverTimeout", "min_heartbeats", "")
+	}
+}
+
+// ClusterSetGatewayResolver keeps the gateway IPs of a cluster up to date if its gateway host is a DNS name,
+// the endpoints of ServiceImports are updated once the gateway host is resolved to other IPs
+type ClusterSetGatewayResolver struct {
+	// RefreshInterval is the max interval in seconds of re-resolving gateway hosts, the TTL of
+	// DNS records is honoured if it's shorter
+	RefreshInterval int32 `json:"refreshInterval" validate:"omitempty,gte=1"`
+}
+
+// ClusterSetGatewayTLS is mTLS between gateways. The control plane issues a certificate to the gateway of
+// each member cluster from its root CA, the certificate identifies the cluster. Endpoints imported from
+// a member carry the server name and CA of its gateway, and the gateway only accepts traffic from clusters
+// which present a certificate of the same CA. It must be enabled in both control plane and member clusters
+type ClusterSetGatewayTLS struct {
+	// Enabled issues gateway certificates to member clusters in control plane, and exposes the Listen port
+	// of ingress-pipy in member clusters. Changing it takes effect once the connectors restart
+	Enabled bool `json:"enabled"`
+	// Listen is the port which ingress-pipy accepts mTLS traffic from other clusters on, the gatewayPort
+	// of Cluster should point to it
+	Listen int32 `json:"listen" validate:"gte=0,lte=65535"`
+}
+
+// ClusterSetEgress is the listener of ingress-pipy which imported services are consumed through. Derived
+// Services and sidecars send requests of imported services to it, ingress-pipy routes them by Host to the
+// gateways of exporting clusters with the exported path, and presents the gateway certificate if the
+// gateway only accepts mTLS. It's only available in ingress-pipy which isn't namespaced
+type ClusterSetEgress struct {
+	// Listen is the port which ingress-pipy accepts traffic of imported services on, 0 disables it.
+	// It's only reachable in cluster by the erie-canal-cluster-egress Service
+	Listen int32 `json:"listen" validate:"gte=0,lte=65535"`
+}
+
+// ClusterSetOutlierDetection is passive outlier detection of imported services. ingress-pipy reports the
+// consecutive failures(5xx, timeouts and connection errors) of targets to manager over mTLS, the exporting
+// cluster of a target is ejected from the ServiceImport once the failures reach the threshold. The ejection
+// time doubles each time the cluster is ejected again, and it's recorded in status of the ServiceImport
+type ClusterSetOutlierDetection struct {
+	// Enabled starts the HTTP server in manager which receives failure reports, and ejected clusters are
+	// excluded from service routes. Changing it requires restarting manager
+	Enabled bool `json:"enabled"`
+	// Port is the HTTP port which failure reports are posted to, changing it requires restarting manager
+	Port int32 `json:"port" validate:"gte=0,lte=65535"`
+	// ReportInterval is the interval in seconds of reporting failures by ingress-pipy
+	ReportInterval int32 `json:"reportInterval" validate:"omitempty,gte=1"`
+	// ConsecutiveFailures is the number of consecutive failures of a target to eject its cluster
+	ConsecutiveFailures int32 `json:"consecutiveFailures" validate:"omitempty,gte=1"`
+	// BaseEjectionTime is the ejection time in seconds of the first ejection
+	BaseEjectionTime int32 `json:"baseEjectionTime" validate:"omitempty,gte=1"`
+	// MaxEjectionTime is the max ejection time in seconds, the ejection count is reset if the cluster
+	// isn't ejected again within it after the last ejection ends
+	MaxEjectionTime int32 `json:"maxEjectionTime" validate:"omitempty,gte=1"`
+	// MaxEjectionPercent is the max percentage of exporting clusters of a ServiceImport which are
+	// ejected at the same time, a cluster is never ejected if it's the only one. It's 50 if not set,
+	// 0 never ejects any cluster
+	MaxEjectionPercent *int32 `json:"maxEjectionPercent,omitempty" validate:"omitempty,gte=0,lte=100"`
+}
+
+type ClusterSetMCSAPI struct {
+	// Enabled translates upstream ServiceExports to flomesh.io ones and mirrors flomesh.io ServiceImports
+	// as upstream ones, the CRDs of multicluster.x-k8s.io must be installed. Changing it requires restarting manager
+	Enabled bool `json:"enabled"`
+}
+
 type Certificate struct {
 	Manager           string `json:"manager" validate:"required"`
 	CaBundleName      string `json:"caBundleName" validate:"required"`
@@ -143,6 +289,152 @@ func (o *MeshConfig) IsControlPlane() bool {
 		o.Cluster.UID == o.Cluster.ControlPlaneUID
 }
 
+// ClusterKey is the key of the cluster in which ErieCanal is running
+func (o *MeshConfig) ClusterKey() string {
+	return util.EvaluateTemplate(commons.ClusterIDTemplate, struct {
+		Region  string
+		Zone    string
+		Group   string
+		Cluster string
+	}{
+		Region:  o.Cluster.Region,
+		Zone:    o.Cluster.Zone,
+		Group:   o.Cluster.Group,
+		Cluster: o.Cluster.Name,
+	})
+}
+
+// HeartbeatInterval returns the interval of renewing the control plane Lease in member clusters
+func (c ClusterSetControlPlane) HeartbeatInterval() time.Duration {
+	if c.HeartbeatPeriod <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(c.HeartbeatPeriod) * time.Second
+}
+
+// LeaseDuration returns how long the control plane Lease is valid since it's renewed, it's
+// 4 heartbeats if unset and never shorter than 2 heartbeats
+func (c ClusterSetControlPlane) LeaseDuration() time.Duration {
+	if c.FailoverTimeout <= 0 {
+		return 4 * c.HeartbeatInterval()
+	}
+
+	duration := time.Duration(c.FailoverTimeout) * time.Second
+	if least := minLeaseHeartbeats * c.HeartbeatInterval(); duration < least {
+		return least
+	}
+
+	return duration
+}
+
+// Interval returns the max interval of re-resolving gateway hosts
+func (c ClusterSetGatewayResolver) Interval() time.Duration {
+	if c.RefreshInterval <= 0 {
+		return 60 * time.Second
+	}
+
+	return time.Duration(c.RefreshInterval) * time.Second
+}
+
+// ReportPeriod returns the interval of reporting failures
+func (c ClusterSetOutlierDetection) ReportPeriod() time.Duration {
+	if c.ReportInterval <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(c.ReportInterval) * time.Second
+}
+
+// Threshold returns the number of consecutive failures to eject a cluster
+func (c ClusterSetOutlierDetection) Threshold() int32 {
+	if c.ConsecutiveFailures <= 0 {
+		return 5
+	}
+
+	return c.ConsecutiveFailures
+}
+
+// MaxEjectedPercent returns the max percentage of exporting clusters which are ejected at the same time
+func (c ClusterSetOutlierDetection) MaxEjectedPercent() int32 {
+	if c.MaxEjectionPercent == nil {
+		return 50
+	}
+
+	return *c.MaxEjectionPercent
+}
+
+// MaxEjection returns the max ejection time
+func (c ClusterSetOutlierDetection) MaxEjection() time.Duration {
+	if c.MaxEjectionTime <= 0 {
+		return 300 * time.Second
+	}
+
+	return time.Duration(c.MaxEjectionTime) * time.Second
+}
+
+// EjectionTime returns how long a cluster is ejected for the n-th consecutive ejection, it's doubled
+// each time and capped by MaxEjection
+func (c ClusterSetOutlierDetection) EjectionTime(n int32) time.Duration {
+	base := 30 * time.Second
+	if c.BaseEjectionTime > 0 {
+		base = time.Duration(c.BaseEjectionTime) * time.Second
+	}
+
+	d := base
+	for i := int32(1); i < n && d < c.MaxEjection(); i++ {
+		d *= 2
+	}
+	if d > c.MaxEjection() {
+		return c.MaxEjection()
+	}
+
+	return d
+}
+
+// IsClusterEgressEnabled returns true if the imported services are consumed through the egress of ingress-pipy
+func (o *MeshConfig) IsClusterEgressEnabled() bool {
+	return o.Ingress.Enabled && !o.Ingress.Namespaced && o.ClusterSet.Egress.Listen > 0
+}
+
+// IsNamespaceExportable returns true if services in the namespace are allowed to be exported
+func (o *MeshConfig) IsNamespaceExportable(namespace string) bool {
+	if len(o.ClusterSet.Namespaces.Exports) == 0 {
+		return true
+	}
+
+	return matchNamespace(o.ClusterSet.Namespaces.Exports, namespace)
+}
+
+// IsNamespaceAutoCreated returns true if the namespace which services are imported into
+// can be created if it doesn't exist
+func (o *MeshConfig) IsNamespaceAutoCreated(namespace string) bool {
+	if len(o.ClusterSet.Namespaces.AutoCreate) == 0 {
+		return true
+	}
+
+	return matchNamespace(o.ClusterSet.Namespaces.AutoCreate, namespace)
+}
+
+// ImportNamespace returns the namespace which the services of exported namespace are imported into
+func (o *MeshConfig) ImportNamespace(namespace string) string {
+	if mapped, ok := o.ClusterSet.Namespaces.Mapping[namespace]; ok && mapped != "" {
+		return mapped
+	}
+
+	return namespace
+}
+
+func matchNamespace(patterns []string, namespace string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, namespace); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (o *MeshConfig) PipyImage() string {
 	return fmt.Sprintf("%s/%s", o.Images.Repository, o.Images.PipyImage)
 }
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"k8s.io/utils/pointer"
+	"testing"
+	"time"
+)
+
+func TestEjectionTime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		od       ClusterSetOutlierDetection
+		n        int32
+		expected time.Duration
+	}{
+		{
+			name:     "first ejection with defaults",
+			n:        1,
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "doubled on each ejection",
+			n:        3,
+			expected: 120 * time.Second,
+		},
+		{
+			name:     "capped by default max ejection time",
+			n:        5,
+			expected: 300 * time.Second,
+		},
+		{
+			name:     "custom base ejection time",
+			od:       ClusterSetOutlierDetection{BaseEjectionTime: 10},
+			n:        2,
+			expected: 20 * time.Second,
+		},
+		{
+			name:     "custom max ejection time",
+			od:       ClusterSetOutlierDetection{BaseEjectionTime: 10, MaxEjectionTime: 25},
+			n:        3,
+			expected: 25 * time.Second,
+		},
+		{
+			name:     "base ejection time longer than max ejection time",
+			od:       ClusterSetOutlierDetection{BaseEjectionTime: 60, MaxEjectionTime: 45},
+			n:        1,
+			expected: 45 * time.Second,
+		},
+		{
+			name:     "no overflow on many ejections",
+			n:        100,
+			expected: 300 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.od.EjectionTime(tc.n); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMaxEjectedPercent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		percent  *int32
+		expected int32
+	}{
+		{
+			name:     "defaults to 50",
+			expected: 50,
+		},
+		{
+			name:     "0 never ejects any cluster",
+			percent:  pointer.Int32(0),
+			expected: 0,
+		},
+		{
+			name:     "explicit percent",
+			percent:  pointer.Int32(100),
+			expected: 100,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			od := ClusterSetOutlierDetection{MaxEjectionPercent: tc.percent}
+			if actual := od.MaxEjectedPercent(); actual != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsNamespaceExportable(t *testing.T) {
+	testCases := []struct {
+		name      string
+		exports   []string
+		namespace string
+		expected  bool
+	}{
+		{
+			name:      "empty allows all namespaces",
+			namespace: "demo",
+			expected:  true,
+		},
+		{
+			name:      "exact namespace",
+			exports:   []string{"demo"},
+			namespace: "demo",
+			expected:  true,
+		},
+		{
+			name:      "namespace not listed",
+			exports:   []string{"demo"},
+			namespace: "demo-2",
+			expected:  false,
+		},
+		{
+			name:      "prefix pattern",
+			exports:   []string{"team-*"},
+			namespace: "team-a",
+			expected:  true,
+		},
+		{
+			name:      "pattern matches the whole namespace",
+			exports:   []string{"team-*"},
+			namespace: "my-team-a",
+			expected:  false,
+		},
+		{
+			name:      "any of the patterns",
+			exports:   []string{"demo", "team-?"},
+			namespace: "team-b",
+			expected:  true,
+		},
+		{
+			name:      "malformed pattern matches nothing",
+			exports:   []string{"team-["},
+			namespace: "team-[",
+			expected:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mc := &MeshConfig{ClusterSet: ClusterSet{Namespaces: ClusterSetNamespaces{Exports: tc.exports}}}
+			if actual := mc.IsNamespaceExportable(tc.namespace); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestImportNamespace(t *testing.T) {
+	mapping := map[string]string{
+		"team-a": "team-a-prod",
+		"team-b": "",
+	}
+
+	testCases := []struct {
+		name      string
+		mapping   map[string]string
+		namespace string
+		expected  string
+	}{
+		{
+			name:      "no mapping keeps the namespace",
+			namespace: "team-a",
+			expected:  "team-a",
+		},
+		{
+			name:      "mapped namespace",
+			mapping:   mapping,
+			namespace: "team-a",
+			expected:  "team-a-prod",
+		},
+		{
+			name:      "empty mapping keeps the namespace",
+			mapping:   mapping,
+			namespace: "team-b",
+			expected:  "team-b",
+		},
+		{
+			name:      "mapping is not a pattern",
+			mapping:   map[string]string{"team-*": "teams"},
+			namespace: "team-c",
+			expected:  "team-c",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mc := &MeshConfig{ClusterSet: ClusterSet{Namespaces: ClusterSetNamespaces{Mapping: tc.mapping}}}
+			if actual := mc.ImportNamespace(tc.namespace); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
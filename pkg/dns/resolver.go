@@ -0,0 +1,417 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import (
+	"fmt"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	svcimpv1alpha1lister "github.com/flomesh-io/ErieCanal/pkg/generated/listers/serviceimport/v1alpha1"
+	"golang.org/x/net/dns/dnsmessage"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// ClusterSetDomain is the zone of multi-cluster services defined by MCS API,
+	// a ServiceImport is resolved as <service>.<namespace>.svc.clusterset.local
+	ClusterSetDomain = commons.ClusterSetDomain + "."
+	ttl              = 5
+)
+
+// Resolver resolves A/AAAA/SRV records of clusterset.local from ServiceImports:
+//   - <svc>.<ns>.svc.clusterset.local, the ClusterIP of the derived Service, or all endpoints if it's Headless
+//   - <endpoint>.<svc>.<ns>.svc.clusterset.local, a single endpoint of a Headless ServiceImport
+//   - <hostname>.<cluster>.<svc>.<ns>.svc.clusterset.local, a single Pod of a Headless ServiceImport
+//   - _<port>._<protocol>.<svc>.<ns>.svc.clusterset.local, SRV records of a named port
+type Resolver struct {
+	serviceImportLister svcimpv1alpha1lister.ServiceImportLister
+	serviceLister       corev1listers.ServiceLister
+}
+
+func NewResolver(serviceImportLister svcimpv1alpha1lister.ServiceImportLister, serviceLister corev1listers.ServiceLister) *Resolver {
+	return &Resolver{
+		serviceImportLister: serviceImportLister,
+		serviceLister:       serviceLister,
+	}
+}
+
+// Handle answers a DNS query in wire format, the response is truncated if it's larger than maxSize
+func (r *Resolver) Handle(req []byte, maxSize int) ([]byte, error) {
+	var query dnsmessage.Message
+	if err := query.Unpack(req); err != nil {
+		return nil, err
+	}
+
+	resp := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               query.ID,
+			Response:         true,
+			OpCode:           query.OpCode,
+			Authoritative:    true,
+			RecursionDesired: query.RecursionDesired,
+		},
+		Questions: query.Questions,
+	}
+
+	switch {
+	case query.OpCode != 0:
+		resp.RCode = dnsmessage.RCodeNotImplemented
+	case len(query.Questions) != 1:
+		resp.RCode = dnsmessage.RCodeFormatError
+	default:
+		resp.Answers, resp.Additionals, resp.RCode = r.Resolve(query.Questions[0])
+	}
+
+	data, err := resp.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxSize {
+		resp.Truncated = true
+		resp.Answers = nil
+		resp.Additionals = nil
+		return resp.Pack()
+	}
+
+	return data, nil
+}
+
+// Resolve returns the answers and additional records of the question
+func (r *Resolver) Resolve(q dnsmessage.Question) ([]dnsmessage.Resource, []dnsmessage.Resource, dnsmessage.RCode) {
+	name := strings.ToLower(q.Name.String())
+	suffix := ".svc." + ClusterSetDomain
+	if !strings.HasSuffix(name, suffix) {
+		if name == ClusterSetDomain || strings.HasSuffix(name, "."+ClusterSetDomain) {
+			return nil, nil, dnsmessage.RCodeNameError
+		}
+		return nil, nil, dnsmessage.RCodeRefused
+	}
+
+	labels := strings.Split(strings.TrimSuffix(name, suffix), ".")
+	switch len(labels) {
+	case 2:
+		// <svc>.<ns>
+		svcImp, rcode := r.getServiceImport(labels[1], labels[0])
+		if svcImp == nil {
+			return nil, nil, rcode
+		}
+		switch q.Type {
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+			return addressRecords(q.Name, q.Type, r.addresses(svcImp)), nil, dnsmessage.RCodeSuccess
+		case dnsmessage.TypeSRV:
+			answers, additionals := r.srvRecords(q.Name, svcImp, svcImp.Spec.Ports)
+			return answers, additionals, dnsmessage.RCodeSuccess
+		}
+	case 3:
+		// <endpoint>.<svc>.<ns>
+		svcImp, rcode := r.getServiceImport(labels[2], labels[1])
+		if svcImp == nil {
+			return nil, nil, rcode
+		}
+		if svcImp.Spec.Type != svcimpv1alpha1.Headless {
+			return nil, nil, dnsmessage.RCodeNameError
+		}
+		ips := endpointIPsOfLabel(svcImp, labels[0])
+		if len(ips) == 0 {
+			return nil, nil, dnsmessage.RCodeNameError
+		}
+		return addressRecords(q.Name, q.Type, ips), nil, dnsmessage.RCodeSuccess
+	case 4:
+		svcImp, rcode := r.getServiceImport(labels[3], labels[2])
+		if svcImp == nil {
+			return nil, nil, rcode
+		}
+
+		if !strings.HasPrefix(labels[0], "_") && !strings.HasPrefix(labels[1], "_") {
+			// <hostname>.<cluster>.<svc>.<ns>
+			ips := podIPs(svcImp, labels[0], labels[1])
+			if len(ips) == 0 {
+				return nil, nil, dnsmessage.RCodeNameError
+			}
+			return addressRecords(q.Name, q.Type, ips), nil, dnsmessage.RCodeSuccess
+		}
+
+		// _<port>._<protocol>.<svc>.<ns>
+		if !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+			return nil, nil, dnsmessage.RCodeNameError
+		}
+		ports := namedPorts(svcImp, labels[0][1:], labels[1][1:])
+		if len(ports) == 0 {
+			return nil, nil, dnsmessage.RCodeNameError
+		}
+		if q.Type == dnsmessage.TypeSRV {
+			answers, additionals := r.srvRecords(q.Name, svcImp, ports)
+			return answers, additionals, dnsmessage.RCodeSuccess
+		}
+	default:
+		return nil, nil, dnsmessage.RCodeNameError
+	}
+
+	// the name exists, but there's no record of the requested type
+	return nil, nil, dnsmessage.RCodeSuccess
+}
+
+func (r *Resolver) getServiceImport(namespace, name string) (*svcimpv1alpha1.ServiceImport, dnsmessage.RCode) {
+	svcImp, err := r.serviceImportLister.ServiceImports(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, dnsmessage.RCodeNameError
+		}
+		klog.Errorf("Failed to get ServiceImport %s/%s: %s", namespace, name, err)
+		return nil, dnsmessage.RCodeServerFailure
+	}
+
+	return svcImp, dnsmessage.RCodeSuccess
+}
+
+// addresses returns the ClusterIPs of the Service derived from the ServiceImport, which are routable by
+// kube-proxy from any Pod, and falls back to the ClusterSetIP which is only routable by sidecars if there's
+// no derived Service yet. For Headless ServiceImport, it returns the IPs of all endpoints
+func (r *Resolver) addresses(svcImp *svcimpv1alpha1.ServiceImport) []string {
+	if svcImp.Spec.Type == svcimpv1alpha1.Headless {
+		return endpointIPs(svcImp)
+	}
+
+	if ips := r.derivedServiceIPs(svcImp); len(ips) > 0 {
+		return ips
+	}
+
+	return svcImp.Spec.IPs
+}
+
+// derivedServiceIPs returns the ClusterIPs of the Service derived from the ServiceImport
+func (r *Resolver) derivedServiceIPs(svcImp *svcimpv1alpha1.ServiceImport) []string {
+	if r.serviceLister == nil {
+		return nil
+	}
+
+	svc, err := r.serviceLister.Services(svcImp.Namespace).Get(svcImp.DerivedServiceName())
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get derived Service of ServiceImport %s/%s: %s", svcImp.Namespace, svcImp.Name, err)
+		}
+		return nil
+	}
+
+	ips := make([]string, 0)
+	for _, ip := range svc.Spec.ClusterIPs {
+		if net.ParseIP(ip) != nil {
+			ips = append(ips, ip)
+		}
+	}
+
+	return ips
+}
+
+// srvRecords returns SRV records of the ports, the targets of SRV records are the service itself for
+// ClusterSetIP ServiceImport, or each endpoint for Headless ServiceImport, their address records are
+// returned as additionals
+func (r *Resolver) srvRecords(qname dnsmessage.Name, svcImp *svcimpv1alpha1.ServiceImport, ports []svcimpv1alpha1.ServicePort) ([]dnsmessage.Resource, []dnsmessage.Resource) {
+	svcName, err := serviceName(svcImp)
+	if err != nil {
+		klog.Errorf("Failed to build DNS name of ServiceImport %s/%s: %s", svcImp.Namespace, svcImp.Name, err)
+		return nil, nil
+	}
+
+	answers := make([]dnsmessage.Resource, 0)
+	// DNS name of target -> IPs of target
+	targets := make(map[string][]string)
+
+	for _, port := range ports {
+		if svcImp.Spec.Type != svcimpv1alpha1.Headless {
+			answers = append(answers, srvRecord(qname, svcName, uint16(port.Port), 100))
+			targets[svcName.String()] = r.addresses(svcImp)
+			continue
+		}
+
+		// endpoints without hostname of the same cluster share the same gateway address
+		portTargets := make(map[string]svcimpv1alpha1.Endpoint)
+		for _, ep := range port.Endpoints {
+			if net.ParseIP(ep.Target.IP) != nil {
+				portTargets[endpointDomain(svcName, ep)] = ep
+			}
+		}
+		if len(portTargets) == 0 {
+			continue
+		}
+
+		weight := uint16(100 / len(portTargets))
+		for _, domain := range sortedKeys(portTargets) {
+			target, err := dnsmessage.NewName(domain)
+			if err != nil {
+				continue
+			}
+			ep := portTargets[domain]
+			answers = append(answers, srvRecord(qname, target, uint16(ep.Target.Port), weight))
+			targets[domain] = ep.Target.AllIPs()
+		}
+	}
+
+	additionals := make([]dnsmessage.Resource, 0)
+	for _, domain := range sortedKeys(targets) {
+		target, err := dnsmessage.NewName(domain)
+		if err != nil {
+			continue
+		}
+		additionals = append(additionals, addressRecords(target, dnsmessage.TypeA, targets[domain])...)
+		additionals = append(additionals, addressRecords(target, dnsmessage.TypeAAAA, targets[domain])...)
+	}
+
+	return answers, additionals
+}
+
+// endpointDomain is the DNS name of an endpoint of Headless ServiceImport, it's
+// <hostname>.<cluster>.<svc>.<ns>.svc.clusterset.local for a Pod with hostname,
+// otherwise <endpoint>.<svc>.<ns>.svc.clusterset.local
+func endpointDomain(svcName dnsmessage.Name, ep svcimpv1alpha1.Endpoint) string {
+	if ep.Hostname != "" {
+		return fmt.Sprintf("%s.%s.%s", ep.Hostname, svcimpv1alpha1.ClusterName(ep.ClusterKey), svcName.String())
+	}
+
+	return fmt.Sprintf("%s.%s", endpointName(ep.Target.IP), svcName.String())
+}
+
+func srvRecord(qname, target dnsmessage.Name, port, weight uint16) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: qname, Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.SRVResource{Priority: 0, Weight: weight, Port: port, Target: target},
+	}
+}
+
+// addressRecords returns A or AAAA records of the IPs which match the type
+func addressRecords(name dnsmessage.Name, qtype dnsmessage.Type, ips []string) []dnsmessage.Resource {
+	records := make([]dnsmessage.Resource, 0)
+	for _, s := range ips {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			continue
+		}
+
+		header := dnsmessage.ResourceHeader{Name: name, Type: qtype, Class: dnsmessage.ClassINET, TTL: ttl}
+		switch {
+		case qtype == dnsmessage.TypeA && ip.To4() != nil:
+			a := &dnsmessage.AResource{}
+			copy(a.A[:], ip.To4())
+			records = append(records, dnsmessage.Resource{Header: header, Body: a})
+		case qtype == dnsmessage.TypeAAAA && ip.To4() == nil:
+			aaaa := &dnsmessage.AAAAResource{}
+			copy(aaaa.AAAA[:], ip.To16())
+			records = append(records, dnsmessage.Resource{Header: header, Body: aaaa})
+		}
+	}
+
+	return records
+}
+
+// namedPorts returns ports of the ServiceImport which match the name and protocol of a SRV query
+func namedPorts(svcImp *svcimpv1alpha1.ServiceImport, name, protocol string) []svcimpv1alpha1.ServicePort {
+	ports := make([]svcimpv1alpha1.ServicePort, 0)
+	for _, port := range svcImp.Spec.Ports {
+		if port.Name == "" || strings.ToLower(port.Name) != name {
+			continue
+		}
+
+		proto := strings.ToLower(string(port.Protocol))
+		if proto == "" {
+			proto = "tcp"
+		}
+		if proto != protocol {
+			continue
+		}
+
+		ports = append(ports, port)
+	}
+
+	return ports
+}
+
+// endpointIPs returns the distinct IPs of all endpoints of the ServiceImport, an endpoint
+// of dual-stack cluster has IPs of both families
+func endpointIPs(svcImp *svcimpv1alpha1.ServiceImport) []string {
+	ips := make(map[string]struct{})
+	for _, port := range svcImp.Spec.Ports {
+		for _, ep := range port.Endpoints {
+			for _, ip := range ep.Target.AllIPs() {
+				if net.ParseIP(ip) != nil {
+					ips[ip] = struct{}{}
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(ips))
+	for ip := range ips {
+		result = append(result, ip)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// endpointIPsOfLabel returns the IPs of the endpoint whose DNS label is the given label,
+// the label is named after the primary IP of the endpoint
+func endpointIPsOfLabel(svcImp *svcimpv1alpha1.ServiceImport, label string) []string {
+	for _, port := range svcImp.Spec.Ports {
+		for _, ep := range port.Endpoints {
+			if net.ParseIP(ep.Target.IP) != nil && endpointName(ep.Target.IP) == label {
+				return ep.Target.AllIPs()
+			}
+		}
+	}
+
+	return nil
+}
+
+// podIPs returns the IPs of the endpoint of the Pod with the hostname in the cluster
+func podIPs(svcImp *svcimpv1alpha1.ServiceImport, hostname, cluster string) []string {
+	for _, port := range svcImp.Spec.Ports {
+		for _, ep := range port.Endpoints {
+			if strings.ToLower(ep.Hostname) == hostname &&
+				strings.ToLower(svcimpv1alpha1.ClusterName(ep.ClusterKey)) == cluster &&
+				net.ParseIP(ep.Target.IP) != nil {
+				return ep.Target.AllIPs()
+			}
+		}
+	}
+
+	return nil
+}
+
+// endpointName is the DNS label of an endpoint, e.g. 10-0-0-1 for 10.0.0.1
+func endpointName(ip string) string {
+	return strings.NewReplacer(".", "-", ":", "-").Replace(ip)
+}
+
+func serviceName(svcImp *svcimpv1alpha1.ServiceImport) (dnsmessage.Name, error) {
+	return dnsmessage.NewName(fmt.Sprintf("%s.%s.svc.%s", svcImp.Name, svcImp.Namespace, ClusterSetDomain))
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
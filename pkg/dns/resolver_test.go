@@ -0,0 +1,383 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import (
+	"context"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	svcimpv1alpha1lister "github.com/flomesh-io/ErieCanal/pkg/generated/listers/serviceimport/v1alpha1"
+	"golang.org/x/net/dns/dnsmessage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// newTestResolver resolves the ServiceImports from an indexer, as the generated fake clientset registers
+// them in another group, and the derived Services from a fake clientset
+func newTestResolver(t *testing.T, imports []*svcimpv1alpha1.ServiceImport, services []runtime.Object) *Resolver {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, imp := range imports {
+		if err := indexer.Add(imp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(services...), 0)
+	resolver := NewResolver(
+		svcimpv1alpha1lister.NewServiceImportLister(indexer),
+		informerFactory.Core().V1().Services().Lister(),
+	)
+
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+
+	return resolver
+}
+
+func newServiceImport(name, namespace string, typ svcimpv1alpha1.ServiceImportType, ips []string, ports ...svcimpv1alpha1.ServicePort) *svcimpv1alpha1.ServiceImport {
+	return &svcimpv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: svcimpv1alpha1.ServiceImportSpec{
+			Type:  typ,
+			IPs:   ips,
+			Ports: ports,
+		},
+	}
+}
+
+func newDerivedService(name, namespace string, clusterIPs ...string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: svcimpv1alpha1.DerivedServiceName(name), Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeClusterIP,
+			ClusterIP:  clusterIPs[0],
+			ClusterIPs: clusterIPs,
+		},
+	}
+}
+
+func question(t *testing.T, name string, qtype dnsmessage.Type) dnsmessage.Question {
+	t.Helper()
+
+	return dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}
+}
+
+// ipsOf returns the sorted IPs of A/AAAA records
+func ipsOf(records []dnsmessage.Resource) []string {
+	ips := make([]string, 0)
+	for _, r := range records {
+		switch body := r.Body.(type) {
+		case *dnsmessage.AResource:
+			ips = append(ips, net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			ips = append(ips, net.IP(body.AAAA[:]).String())
+		}
+	}
+	sort.Strings(ips)
+
+	return ips
+}
+
+func TestResolveAddresses(t *testing.T) {
+	httpPort := svcimpv1alpha1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	gateway := func(clusterKey, hostname string, ips ...string) svcimpv1alpha1.Endpoint {
+		return svcimpv1alpha1.Endpoint{
+			Target:     svcimpv1alpha1.Target{IP: ips[0], IPs: ips, Port: 8000},
+			ClusterKey: clusterKey,
+			Hostname:   hostname,
+		}
+	}
+	headlessPort := httpPort
+	headlessPort.Endpoints = []svcimpv1alpha1.Endpoint{
+		gateway("default/default/default/cluster1", "web-0", "10.0.1.1"),
+		gateway("default/default/default/cluster2", "web-0", "10.0.2.1", "fd00::2:1"),
+	}
+
+	imports := []*svcimpv1alpha1.ServiceImport{
+		newServiceImport("derived", "demo", svcimpv1alpha1.ClusterSetIP, []string{"198.18.0.1"}, httpPort),
+		newServiceImport("vip-only", "demo", svcimpv1alpha1.ClusterSetIP, []string{"198.18.0.2"}, httpPort),
+		newServiceImport("dual", "demo", svcimpv1alpha1.ClusterSetIP, nil, httpPort),
+		newServiceImport("web", "demo", svcimpv1alpha1.Headless, nil, headlessPort),
+	}
+	services := []runtime.Object{
+		newDerivedService("derived", "demo", "10.96.0.10"),
+		newDerivedService("dual", "demo", "10.96.0.11", "fd00::11"),
+	}
+	resolver := newTestResolver(t, imports, services)
+
+	testCases := []struct {
+		name  string
+		qname string
+		qtype dnsmessage.Type
+		rcode dnsmessage.RCode
+		ips   []string
+	}{
+		{
+			name:  "ClusterIP of derived Service is preferred over VIP",
+			qname: "derived.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{"10.96.0.10"},
+		},
+		{
+			name:  "VIP without derived Service",
+			qname: "vip-only.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{"198.18.0.2"},
+		},
+		{
+			name:  "AAAA of dual-stack derived Service",
+			qname: "dual.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeAAAA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{"fd00::11"},
+		},
+		{
+			name:  "no AAAA of single-stack derived Service",
+			qname: "derived.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeAAAA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{},
+		},
+		{
+			name:  "case insensitive",
+			qname: "Derived.Demo.svc.ClusterSet.Local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{"10.96.0.10"},
+		},
+		{
+			name:  "all endpoints of headless ServiceImport",
+			qname: "web.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{"10.0.1.1", "10.0.2.1"},
+		},
+		{
+			name:  "single endpoint of headless ServiceImport",
+			qname: "10-0-2-1.web.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeAAAA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{"fd00::2:1"},
+		},
+		{
+			name:  "Pod of headless ServiceImport",
+			qname: "web-0.cluster1.web.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeSuccess,
+			ips:   []string{"10.0.1.1"},
+		},
+		{
+			name:  "unknown Pod of headless ServiceImport",
+			qname: "web-1.cluster1.web.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "endpoint of ClusterSetIP ServiceImport",
+			qname: "10-0-1-1.derived.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "unknown ServiceImport",
+			qname: "unknown.demo.svc.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "unknown name in clusterset zone",
+			qname: "demo.clusterset.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "name out of clusterset zone",
+			qname: "derived.demo.svc.cluster.local.",
+			qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeRefused,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			answers, _, rcode := resolver.Resolve(question(t, tc.qname, tc.qtype))
+			if rcode != tc.rcode {
+				t.Fatalf("expected rcode %s, got %s", tc.rcode, rcode)
+			}
+			if tc.ips == nil {
+				return
+			}
+			if ips := ipsOf(answers); !reflect.DeepEqual(ips, tc.ips) {
+				t.Errorf("expected %v, got %v", tc.ips, ips)
+			}
+		})
+	}
+}
+
+func TestResolveSRV(t *testing.T) {
+	ports := []svcimpv1alpha1.ServicePort{
+		{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80},
+		{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53},
+	}
+	headlessPort := svcimpv1alpha1.ServicePort{
+		Name:     "http",
+		Protocol: corev1.ProtocolTCP,
+		Port:     80,
+		Endpoints: []svcimpv1alpha1.Endpoint{
+			{
+				Target:     svcimpv1alpha1.Target{IP: "10.0.1.1", Port: 8001},
+				ClusterKey: "default/default/default/cluster1",
+				Hostname:   "web-0",
+			},
+			{
+				Target:     svcimpv1alpha1.Target{IP: "10.0.2.1", Port: 8002},
+				ClusterKey: "default/default/default/cluster2",
+			},
+		},
+	}
+
+	imports := []*svcimpv1alpha1.ServiceImport{
+		newServiceImport("api", "demo", svcimpv1alpha1.ClusterSetIP, []string{"198.18.0.1"}, ports...),
+		newServiceImport("web", "demo", svcimpv1alpha1.Headless, nil, headlessPort),
+	}
+	services := []runtime.Object{
+		newDerivedService("api", "demo", "10.96.0.10"),
+	}
+	resolver := newTestResolver(t, imports, services)
+
+	type srv struct {
+		target string
+		port   uint16
+	}
+
+	testCases := []struct {
+		name        string
+		qname       string
+		rcode       dnsmessage.RCode
+		srvs        []srv
+		additionals []string
+	}{
+		{
+			name:        "all ports of ClusterSetIP ServiceImport",
+			qname:       "api.demo.svc.clusterset.local.",
+			rcode:       dnsmessage.RCodeSuccess,
+			srvs:        []srv{{"api.demo.svc.clusterset.local.", 80}, {"api.demo.svc.clusterset.local.", 53}},
+			additionals: []string{"10.96.0.10"},
+		},
+		{
+			name:        "named port of ClusterSetIP ServiceImport",
+			qname:       "_dns._udp.api.demo.svc.clusterset.local.",
+			rcode:       dnsmessage.RCodeSuccess,
+			srvs:        []srv{{"api.demo.svc.clusterset.local.", 53}},
+			additionals: []string{"10.96.0.10"},
+		},
+		{
+			name:  "named port with another protocol",
+			qname: "_dns._tcp.api.demo.svc.clusterset.local.",
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "endpoints of headless ServiceImport",
+			qname: "_http._tcp.web.demo.svc.clusterset.local.",
+			rcode: dnsmessage.RCodeSuccess,
+			srvs: []srv{
+				{"10-0-2-1.web.demo.svc.clusterset.local.", 8002},
+				{"web-0.cluster1.web.demo.svc.clusterset.local.", 8001},
+			},
+			additionals: []string{"10.0.1.1", "10.0.2.1"},
+		},
+		{
+			name:  "unknown ServiceImport",
+			qname: "_http._tcp.unknown.demo.svc.clusterset.local.",
+			rcode: dnsmessage.RCodeNameError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			answers, additionals, rcode := resolver.Resolve(question(t, tc.qname, dnsmessage.TypeSRV))
+			if rcode != tc.rcode {
+				t.Fatalf("expected rcode %s, got %s", tc.rcode, rcode)
+			}
+
+			srvs := make([]srv, 0)
+			for _, a := range answers {
+				body, ok := a.Body.(*dnsmessage.SRVResource)
+				if !ok {
+					t.Fatalf("expected SRV record, got %s", a.Header.Type)
+				}
+				srvs = append(srvs, srv{body.Target.String(), body.Port})
+			}
+			if len(tc.srvs) > 0 && !reflect.DeepEqual(srvs, tc.srvs) {
+				t.Errorf("expected SRV records %v, got %v", tc.srvs, srvs)
+			}
+			if ips := ipsOf(additionals); len(tc.additionals) > 0 && !reflect.DeepEqual(ips, tc.additionals) {
+				t.Errorf("expected additionals %v, got %v", tc.additionals, ips)
+			}
+		})
+	}
+}
+
+func TestHandleTruncatesLargeResponse(t *testing.T) {
+	port := svcimpv1alpha1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	for i := 0; i < 64; i++ {
+		ip := net.IPv4(10, 0, byte(i), 1).String()
+		port.Endpoints = append(port.Endpoints, svcimpv1alpha1.Endpoint{
+			Target:     svcimpv1alpha1.Target{IP: ip, Port: 8000},
+			ClusterKey: "default/default/default/cluster" + ip,
+		})
+	}
+	resolver := newTestResolver(t, []*svcimpv1alpha1.ServiceImport{
+		newServiceImport("web", "demo", svcimpv1alpha1.Headless, nil, port),
+	}, nil)
+
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 1, RecursionDesired: true},
+		Questions: []dnsmessage.Question{question(t, "web.demo.svc.clusterset.local.", dnsmessage.TypeA)},
+	}
+	req, err := query.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := resolver.Handle(req, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Truncated || len(resp.Answers) != 0 || resp.ID != query.ID {
+		t.Errorf("expected truncated response without answers, got truncated=%v answers=%d", resp.Truncated, len(resp.Answers))
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	ecinformers "github.com/flomesh-io/ErieCanal/pkg/generated/informers/externalversions"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/klog/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// maxUDPSize is the max size of a DNS message over UDP without EDNS0,
+	// larger responses are truncated and clients are expected to retry over TCP
+	maxUDPSize = 512
+	maxTCPSize = 65535
+	tcpTimeout = 10 * time.Second
+)
+
+// Server answers DNS queries of clusterset.local zone, it serves both UDP and TCP on the same address
+type Server struct {
+	addr              string
+	resolver          *Resolver
+	ecInformerFactory ecinformers.SharedInformerFactory
+	informerFactory   informers.SharedInformerFactory
+}
+
+func NewServer(addr string, api *kube.K8sAPI, resyncPeriod time.Duration) *Server {
+	ecInformerFactory := ecinformers.NewSharedInformerFactoryWithOptions(api.FlomeshClient, resyncPeriod)
+	// only derived Services are needed for resolving ServiceImports
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(api.Client, resyncPeriod,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = commons.ServiceNameLabel
+		}),
+	)
+
+	return &Server{
+		addr: addr,
+		resolver: NewResolver(
+			ecInformerFactory.Serviceimport().V1alpha1().ServiceImports().Lister(),
+			informerFactory.Core().V1().Services().Lister(),
+		),
+		ecInformerFactory: ecInformerFactory,
+		informerFactory:   informerFactory,
+	}
+}
+
+// Start implements manager.Runnable, it blocks until the context is done
+func (s *Server) Start(ctx context.Context) error {
+	s.ecInformerFactory.Start(ctx.Done())
+	s.informerFactory.Start(ctx.Done())
+	s.ecInformerFactory.WaitForCacheSync(ctx.Done())
+	s.informerFactory.WaitForCacheSync(ctx.Done())
+
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	klog.Infof("DNS server of %s is listening on %s", ClusterSetDomain, s.addr)
+
+	errCh := make(chan error, 2)
+	go func() { errCh <- s.serveUDP(conn) }()
+	go func() { errCh <- s.serveTCP(ln) }()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica serves DNS queries
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) serveUDP(conn net.PacketConn) error {
+	buf := make([]byte, maxTCPSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		resp, err := s.resolver.Handle(buf[:n], maxUDPSize)
+		if err != nil {
+			klog.V(5).Infof("Failed to handle DNS query from %s: %s", addr, err)
+			continue
+		}
+
+		if _, err := conn.WriteTo(resp, addr); err != nil {
+			klog.V(5).Infof("Failed to write DNS response to %s: %s", addr, err)
+		}
+	}
+}
+
+func (s *Server) serveTCP(ln net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleTCPConn(conn)
+		}()
+	}
+}
+
+// handleTCPConn serves queries on the connection until the client closes it or it's idle for too long,
+// each message is prefixed with a two byte length field
+func (s *Server) handleTCPConn(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		if err := conn.SetDeadline(time.Now().Add(tcpTimeout)); err != nil {
+			return
+		}
+
+		var length uint16
+		if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+			return
+		}
+
+		req := make([]byte, length)
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+
+		resp, err := s.resolver.Handle(req, maxTCPSize)
+		if err != nil {
+			klog.V(5).Infof("Failed to handle DNS query from %s: %s", conn.RemoteAddr(), err)
+			return
+		}
+
+		msg := make([]byte, 2+len(resp))
+		binary.BigEndian.PutUint16(msg, uint16(len(resp)))
+		copy(msg[2:], resp)
+		if _, err := conn.Write(msg); err != nil {
+			return
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
 	"github.com/flomesh-io/ErieCanal/pkg/config"
 	corev1 "k8s.io/api/core/v1"
+	"time"
 )
 
 type EventType string
@@ -29,6 +30,12 @@ const (
 	ServiceExportDeleted  EventType = "service.export.deleted"
 	ServiceExportAccepted EventType = "service.export.accepted"
 	ServiceExportRejected EventType = "service.export.rejected"
+	ServiceExportsSynced  EventType = "service.exports.synced"
+	ServiceImportReported EventType = "service.import.reported"
+	ClusterProbed         EventType = "cluster.probed"
+	ClusterUnhealthy      EventType = "cluster.unhealthy"
+	ClusterRecovered      EventType = "cluster.recovered"
+	ClusterTakenOver      EventType = "cluster.takenover"
 )
 
 type Message struct {
@@ -56,6 +63,58 @@ func (e *ServiceExportEvent) ClusterKey() string {
 	return e.Geo.Key()
 }
 
+// ServiceExportSyncEvent carries all exports of a cluster once its cache is synced,
+// the imports of exports which no longer exist are pruned by other clusters
+type ServiceExportSyncEvent struct {
+	Geo            *config.ConnectorConfig
+	ServiceExports []*svcexpv1alpha1.ServiceExport
+}
+
+func (e *ServiceExportSyncEvent) ClusterKey() string {
+	return e.Geo.Key()
+}
+
+// ServiceImportReportEvent is the result of importing a ServiceExport into a cluster,
+// it's reported back to the exporting cluster
+type ServiceImportReportEvent struct {
+	// Geo of the exporting cluster
+	Geo           *config.ConnectorConfig
+	ServiceExport *svcexpv1alpha1.ServiceExport
+	// ImportClusterKey is the key of the importing cluster
+	ImportClusterKey string
+	Imported         bool
+	Error            string
+	// Withdrawn is true if the cluster is no longer a target of the export
+	Withdrawn  bool
+	ReportTime time.Time
+}
+
+func (e *ServiceImportReportEvent) ClusterKey() string {
+	return e.Geo.Key()
+}
+
+// ClusterProbeEvent is the result of probing a remote cluster
+type ClusterProbeEvent struct {
+	Geo                *config.ConnectorConfig
+	ProbeTime          time.Time
+	APIServerReachable bool
+	APIServerError     string
+	GatewayReachable   bool
+	GatewayError       string
+	// Active is true if the control plane holds the Lease of the cluster, it's standing by otherwise
+	Active bool
+}
+
+func (e *ClusterProbeEvent) ClusterKey() string {
+	return e.Geo.Key()
+}
+
+// Healthy returns true if the gateway of the cluster is reachable, the API server isn't taken into account
+// as the traffic from other clusters still works if only the control plane can't reach it
+func (e *ClusterProbeEvent) Healthy() bool {
+	return e.GatewayReachable
+}
+
 //func NewServiceExportMessage(eventType EventType, geo *config.ConnectorConfig, serviceExport *svcexpv1alpha1.ServiceExport, svc *corev1.Service, data map[string]interface{}) *Message {
 //	obj := ServiceExportEvent{Geo: geo, ServiceExport: serviceExport, Service: svc, Data: data}
 //
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipam
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+)
+
+// Allocator allocates IPs from a CIDR, each IP is owned by exactly one owner.
+// The allocations are NOT persisted by Allocator itself, the owners are expected to
+// persist the IPs(e.g. in spec of the resource), and Assign them back after restarting.
+type Allocator struct {
+	mu      sync.Mutex
+	cidr    *net.IPNet
+	base    *big.Int
+	size    int64
+	owners  map[string]string // owner -> IP
+	ips     map[string]string // IP -> owner
+	current int64
+}
+
+func NewAllocator(cidr string) (*Allocator, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %s", cidr, err)
+	}
+
+	// IPv6 is not supported, neither IPv4-mapped IPv6 CIDRs like ::ffff:10.0.0.0/104
+	if ipNet.IP.To4() == nil || len(ipNet.Mask) != net.IPv4len {
+		return nil, fmt.Errorf("CIDR %q is not an IPv4 CIDR, IPv6 is not supported", cidr)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones < 2 {
+		return nil, fmt.Errorf("CIDR %q is too small", cidr)
+	}
+
+	return &Allocator{
+		cidr: ipNet,
+		base: big.NewInt(0).SetBytes(ipNet.IP.To4()),
+		// excludes the network address and broadcast address
+		size:   int64(1)<<(bits-ones) - 2,
+		owners: make(map[string]string),
+		ips:    make(map[string]string),
+	}, nil
+}
+
+// CIDR returns the CIDR which IPs are allocated from
+func (a *Allocator) CIDR() string {
+	return a.cidr.String()
+}
+
+// Contains returns true if the IP is in the CIDR
+func (a *Allocator) Contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	return a.cidr.Contains(parsed)
+}
+
+// Get returns the IP which is allocated to the owner
+func (a *Allocator) Get(owner string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ip, ok := a.owners[owner]
+	return ip, ok
+}
+
+// Assign records the IP is owned by the owner, it fails if the IP is out of range, it's the
+// network or broadcast address, or it's owned by another owner.
+func (a *Allocator) Assign(owner, ip string) error {
+	if !a.Contains(ip) {
+		return fmt.Errorf("IP %s is not in range %s", ip, a.cidr)
+	}
+
+	offset := a.offsetOf(net.ParseIP(ip))
+	if offset < 1 || offset > a.size {
+		return fmt.Errorf("IP %s is the network or broadcast address of %s", ip, a.cidr)
+	}
+	// the IP is recorded in canonical form, the same as the allocated ones
+	ip = a.ipAt(offset)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if o, ok := a.ips[ip]; ok && o != owner {
+		return fmt.Errorf("IP %s is already allocated to %s", ip, o)
+	}
+
+	a.release(owner)
+	a.owners[owner] = ip
+	a.ips[ip] = owner
+
+	return nil
+}
+
+// Allocate allocates a free IP to the owner, the IPs which are reserved are skipped.
+// If the owner already has an IP and it's not reserved, the same IP is returned.
+func (a *Allocator) Allocate(owner string, reserved map[string]bool) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if ip, ok := a.owners[owner]; ok && !reserved[ip] {
+		return ip, nil
+	}
+
+	for i := int64(0); i < a.size; i++ {
+		offset := (a.current+i)%a.size + 1
+		ip := a.ipAt(offset)
+		if _, ok := a.ips[ip]; ok || reserved[ip] {
+			continue
+		}
+
+		a.release(owner)
+		a.owners[owner] = ip
+		a.ips[ip] = owner
+		a.current = offset % a.size
+
+		return ip, nil
+	}
+
+	return "", fmt.Errorf("no free IP in range %s", a.cidr)
+}
+
+// Release frees the IP owned by the owner
+func (a *Allocator) Release(owner string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.release(owner)
+}
+
+func (a *Allocator) release(owner string) {
+	if ip, ok := a.owners[owner]; ok {
+		delete(a.ips, ip)
+		delete(a.owners, owner)
+	}
+}
+
+func (a *Allocator) offsetOf(ip net.IP) int64 {
+	return big.NewInt(0).Sub(big.NewInt(0).SetBytes(ip.To4()), a.base).Int64()
+}
+
+func (a *Allocator) ipAt(offset int64) string {
+	ip := big.NewInt(0).Add(a.base, big.NewInt(offset)).Bytes()
+	// pads to 4 bytes, in case of leading zeros
+	padded := make([]byte, net.IPv4len)
+	copy(padded[net.IPv4len-len(ip):], ip)
+
+	return net.IP(padded).String()
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipam
+
+import (
+	"testing"
+)
+
+func TestNewAllocator(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cidr    string
+		wantErr bool
+	}{
+		{name: "IPv4 CIDR", cidr: "241.0.0.0/16"},
+		{name: "smallest usable CIDR", cidr: "241.0.0.0/30"},
+		{name: "CIDR without host addresses", cidr: "241.0.0.0/31", wantErr: true},
+		{name: "IPv6 CIDR", cidr: "fd00::/120", wantErr: true},
+		{name: "IPv4-mapped IPv6 CIDR", cidr: "::ffff:241.0.0.0/112", wantErr: true},
+		{name: "malformed CIDR", cidr: "241.0.0.0", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAllocator(tc.cidr)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	testCases := []struct {
+		name    string
+		owner   string
+		ip      string
+		wantErr bool
+	}{
+		{name: "host address", owner: "demo/new", ip: "241.0.0.2"},
+		{name: "re-assign the same IP to its owner", owner: "demo/owned", ip: "241.0.0.1"},
+		{name: "IP owned by another owner", owner: "demo/new", ip: "241.0.0.1", wantErr: true},
+		{name: "network address", owner: "demo/new", ip: "241.0.0.0", wantErr: true},
+		{name: "broadcast address", owner: "demo/new", ip: "241.0.0.7", wantErr: true},
+		{name: "out of range", owner: "demo/new", ip: "241.0.1.1", wantErr: true},
+		{name: "malformed IP", owner: "demo/new", ip: "241.0.0", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := NewAllocator("241.0.0.0/29")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := a.Assign("demo/owned", "241.0.0.1"); err != nil {
+				t.Fatal(err)
+			}
+
+			err = a.Assign(tc.owner, tc.ip)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if ip, _ := a.Get(tc.owner); !tc.wantErr && ip != tc.ip {
+				t.Errorf("expected %s to own %s, got %q", tc.owner, tc.ip, ip)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		owned    map[string]string
+		owner    string
+		reserved map[string]bool
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "first host address",
+			owner:    "demo/a",
+			expected: "241.0.0.1",
+		},
+		{
+			name:     "existing IP of the owner",
+			owned:    map[string]string{"demo/a": "241.0.0.2"},
+			owner:    "demo/a",
+			expected: "241.0.0.2",
+		},
+		{
+			name:     "IPs of other owners are skipped",
+			owned:    map[string]string{"demo/b": "241.0.0.1"},
+			owner:    "demo/a",
+			expected: "241.0.0.2",
+		},
+		{
+			name:     "reserved IPs are skipped",
+			owner:    "demo/a",
+			reserved: map[string]bool{"241.0.0.1": true},
+			expected: "241.0.0.2",
+		},
+		{
+			name:     "reserved IP of the owner is re-allocated",
+			owned:    map[string]string{"demo/a": "241.0.0.1"},
+			owner:    "demo/a",
+			reserved: map[string]bool{"241.0.0.1": true},
+			expected: "241.0.0.2",
+		},
+		{
+			name:     "no free IP",
+			owned:    map[string]string{"demo/b": "241.0.0.1"},
+			owner:    "demo/a",
+			reserved: map[string]bool{"241.0.0.2": true},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := NewAllocator("241.0.0.0/30")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for owner, ip := range tc.owned {
+				if err := a.Assign(owner, ip); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ip, err := a.Allocate(tc.owner, tc.reserved)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if ip != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, ip)
+			}
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	a, err := NewAllocator("241.0.0.0/30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Assign("demo/a", "241.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Release("demo/a")
+	if _, ok := a.Get("demo/a"); ok {
+		t.Errorf("expected demo/a to own no IP after release")
+	}
+	if err := a.Assign("demo/b", "241.0.0.1"); err != nil {
+		t.Errorf("expected the released IP to be assignable, got %v", err)
+	}
+}
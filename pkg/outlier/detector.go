@@ -0,0 +1,199 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+import (
+	"context"
+	"fmt"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	flomesh "github.com/flomesh-io/ErieCanal/pkg/generated/clientset/versioned"
+	svcimpv1alpha1lister "github.com/flomesh-io/ErieCanal/pkg/generated/listers/serviceimport/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Detector ejects the exporting cluster of a target from ServiceImports once the consecutive failures
+// of the target reach the threshold, the ejection is recorded in status of the ServiceImport
+type Detector struct {
+	client              flomesh.Interface
+	serviceImportLister svcimpv1alpha1lister.ServiceImportLister
+	configStore         *config.Store
+}
+
+func NewDetector(client flomesh.Interface, serviceImportLister svcimpv1alpha1lister.ServiceImportLister, configStore *config.Store) *Detector {
+	return &Detector{
+		client:              client,
+		serviceImportLister: serviceImportLister,
+		configStore:         configStore,
+	}
+}
+
+// ejectionKey is an exporting cluster of a ServiceImport
+type ejectionKey struct {
+	serviceImport types.NamespacedName
+	clusterKey    string
+}
+
+// Detect ejects the exporting clusters of the target in a report if it fails too many times
+func (d *Detector) Detect(report Report) {
+	od := d.configStore.MeshConfig.GetConfig().ClusterSet.OutlierDetection
+	if report.ConsecutiveFailures < od.Threshold() {
+		return
+	}
+
+	keys, err := d.clustersOf(report)
+	if err != nil {
+		klog.V(5).Infof("Ignore failure report of %s: %s", report.Target, err)
+		return
+	}
+
+	for _, key := range keys {
+		if err := d.eject(key, report, od); err != nil {
+			klog.Errorf("Failed to eject cluster %s from ServiceImport %s: %s", key.clusterKey, key.serviceImport, err)
+		}
+	}
+}
+
+// clustersOf returns the exporting clusters of the reported ServiceImport whose gateway is the target
+func (d *Detector) clustersOf(report Report) ([]ejectionKey, error) {
+	host, port, err := net.SplitHostPort(report.Target)
+	if err != nil {
+		return nil, err
+	}
+	targetPort, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not an IP", host)
+	}
+
+	imp, err := d.serviceImportOf(report.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ejectionKey, 0)
+	clusters := make(map[string]bool)
+	for _, p := range imp.Spec.Ports {
+		for _, ep := range p.Endpoints {
+			if int(ep.Target.Port) != targetPort || clusters[ep.ClusterKey] || !hasIP(ep.Target, ip) {
+				continue
+			}
+
+			clusters[ep.ClusterKey] = true
+			result = append(result, ejectionKey{
+				serviceImport: types.NamespacedName{Namespace: imp.Namespace, Name: imp.Name},
+				clusterKey:    ep.ClusterKey,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// serviceImportOf returns the ServiceImport of service in namespace/name[:port] format
+func (d *Detector) serviceImportOf(service string) (*svcimpv1alpha1.ServiceImport, error) {
+	namespace, name, ok := strings.Cut(service, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("%q is not in namespace/name format", service)
+	}
+	name, _, _ = strings.Cut(name, ":")
+
+	return d.serviceImportLister.ServiceImports(namespace).Get(name)
+}
+
+// eject sets the ejection of cluster in status of the ServiceImport, the cluster isn't ejected if it's
+// already ejected or the max ejection percent is reached
+func (d *Detector) eject(key ejectionKey, report Report, od config.ClusterSetOutlierDetection) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		imp, err := d.client.ServiceimportV1alpha1().
+			ServiceImports(key.serviceImport.Namespace).
+			Get(context.TODO(), key.serviceImport.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		idx := -1
+		ejected := 0
+		for i, cs := range imp.Status.Clusters {
+			if cs.Cluster == key.clusterKey {
+				idx = i
+			} else if cs.Ejection.IsEjected(now) {
+				ejected++
+			}
+		}
+
+		// the status is updated by the ServiceImport controller later
+		if idx < 0 {
+			return nil
+		}
+
+		last := imp.Status.Clusters[idx].Ejection
+		if last.IsEjected(now) {
+			return nil
+		}
+
+		if (ejected+1)*100 > int(od.MaxEjectedPercent())*len(imp.Status.Clusters) {
+			klog.V(2).Infof("Max ejection percent %d%% of ServiceImport %s is reached, cluster %s isn't ejected",
+				od.MaxEjectedPercent(), key.serviceImport, key.clusterKey)
+			return nil
+		}
+
+		// the ejection time is reset if the cluster keeps healthy for a while since last ejection
+		ejections := int32(1)
+		if last != nil && now.Sub(last.EjectedUntil.Time) < od.MaxEjection() {
+			ejections = last.Ejections + 1
+		}
+
+		ejectionTime := od.EjectionTime(ejections)
+		imp.Status.Clusters[idx].Ejection = &svcimpv1alpha1.ClusterEjection{
+			EjectedUntil: metav1.NewTime(now.Add(ejectionTime)),
+			Ejections:    ejections,
+			Reason:       fmt.Sprintf("%d consecutive failures of gateway %s", report.ConsecutiveFailures, report.Target),
+		}
+
+		if _, err := d.client.ServiceimportV1alpha1().
+			ServiceImports(imp.Namespace).
+			UpdateStatus(context.TODO(), imp, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+
+		klog.Infof("Cluster %s is ejected from ServiceImport %s for %s, %d consecutive failures of gateway %s",
+			key.clusterKey, key.serviceImport, ejectionTime, report.ConsecutiveFailures, report.Target)
+		return nil
+	})
+}
+
+func hasIP(target svcimpv1alpha1.Target, ip net.IP) bool {
+	for _, s := range target.AllIPs() {
+		if ip.Equal(net.ParseIP(s)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+import (
+	mapset "github.com/deckarep/golang-set/v2"
+	svcimpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceimport/v1alpha1"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"time"
+)
+
+// EjectedClusters returns the exporting clusters of the ServiceImport which are unhealthy or ejected by
+// outlier detection, and the earliest time one of the ejections expires. Both the service routes and
+// the derived Service leave them out, so that traffic through either of them is consistent
+func EjectedClusters(svcImp *svcimpv1alpha1.ServiceImport, od config.ClusterSetOutlierDetection, now time.Time) (mapset.Set[string], time.Time) {
+	ejected := mapset.NewSet[string]()
+	var until time.Time
+
+	for _, cs := range svcImp.Status.Clusters {
+		if cs.Unhealthy {
+			ejected.Add(cs.Cluster)
+			continue
+		}
+
+		if !od.Enabled || !cs.Ejection.IsEjected(now) {
+			continue
+		}
+
+		ejected.Add(cs.Cluster)
+		if until.IsZero() || cs.Ejection.EjectedUntil.Time.Before(until) {
+			until = cs.Ejection.EjectedUntil.Time
+		}
+	}
+
+	return ejected, until
+}
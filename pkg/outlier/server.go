@@ -0,0 +1,129 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"github.com/flomesh-io/ErieCanal/pkg/certificate"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	ecinformers "github.com/flomesh-io/ErieCanal/pkg/generated/informers/externalversions"
+	"github.com/flomesh-io/ErieCanal/pkg/kube"
+	"io"
+	"k8s.io/klog/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// maxReportSize is the max size of request body of failure reports
+	maxReportSize = 1 << 20
+	readTimeout   = 10 * time.Second
+)
+
+// Server receives failure reports from ingress-pipy over mTLS, and ejects the exporting clusters
+// of failing targets from ServiceImports
+type Server struct {
+	addr              string
+	certMgr           certificate.Manager
+	detector          *Detector
+	ecInformerFactory ecinformers.SharedInformerFactory
+}
+
+func NewServer(addr string, api *kube.K8sAPI, configStore *config.Store, certMgr certificate.Manager, resyncPeriod time.Duration) *Server {
+	ecInformerFactory := ecinformers.NewSharedInformerFactoryWithOptions(api.FlomeshClient, resyncPeriod)
+
+	return &Server{
+		addr:    addr,
+		certMgr: certMgr,
+		detector: NewDetector(
+			api.FlomeshClient,
+			ecInformerFactory.Serviceimport().V1alpha1().ServiceImports().Lister(),
+			configStore,
+		),
+		ecInformerFactory: ecInformerFactory,
+	}
+}
+
+// Start implements manager.Runnable, it blocks until the context is done
+func (s *Server) Start(ctx context.Context) error {
+	s.ecInformerFactory.Start(ctx.Done())
+	s.ecInformerFactory.WaitForCacheSync(ctx.Done())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ReportPath, s.handleReports)
+	server := &http.Server{
+		Addr:        s.addr,
+		Handler:     mux,
+		ReadTimeout: readTimeout,
+	}
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		klog.Infof("Outlier detection is listening on %s", s.addr)
+		errCh <- server.Serve(tls.NewListener(ln, &tls.Config{GetConfigForClient: s.tlsConfig}))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return server.Shutdown(context.Background())
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica receives reports
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxReportSize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	reports := &Reports{}
+	if err := json.Unmarshal(body, reports); err != nil {
+		klog.V(5).Infof("Invalid failure reports from %s: %s", r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, report := range reports.Reports {
+		s.detector.Detect(report)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
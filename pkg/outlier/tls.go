@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/flomesh-io/ErieCanal/pkg/certificate"
+	"github.com/flomesh-io/ErieCanal/pkg/commons"
+	"github.com/flomesh-io/ErieCanal/pkg/config"
+	"sync"
+	"time"
+)
+
+const (
+	// ReporterName is the common name of the client certificate which ingress-pipy reports failures with,
+	// reports from any other client are rejected
+	ReporterName = "erie-canal-outlier-reporter"
+
+	certValidityPeriod = 365 * 24 * time.Hour
+	// certRenewBefore is how long before expiration the certificates are renewed
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+// certLock serializes issuing certificates, the reporter certificate is issued when ingress config is
+// built while the server certificate is issued on TLS handshakes
+var certLock sync.Mutex
+
+// ReporterCertificate returns the client certificate of ingress-pipy for reporting failures
+func ReporterCertificate(certMgr certificate.Manager) (*certificate.Certificate, error) {
+	return getOrIssueCertificate(certMgr, ReporterName, nil)
+}
+
+// ServerName is the host which ingress-pipy posts failure reports to, it's the Service of manager
+func ServerName() string {
+	return fmt.Sprintf("%s.%s.svc", commons.ManagerServiceName, config.GetErieCanalNamespace())
+}
+
+// getOrIssueCertificate returns the certificate of cn issued before, a new one is issued if it's about to expire
+func getOrIssueCertificate(certMgr certificate.Manager, cn string, dnsNames []string) (*certificate.Certificate, error) {
+	certLock.Lock()
+	defer certLock.Unlock()
+
+	if cert, err := certMgr.GetCertificate(cn); err == nil && time.Now().Add(certRenewBefore).Before(cert.Expiration) {
+		return cert, nil
+	}
+
+	return certMgr.IssueCertificate(cn, certValidityPeriod, dnsNames)
+}
+
+// tlsConfig only accepts clients presenting the reporter certificate issued by the root CA, it's built
+// on each handshake so that renewed certificates are picked up
+func (s *Server) tlsConfig(*tls.ClientHelloInfo) (*tls.Config, error) {
+	cert, err := getOrIssueCertificate(s.certMgr, ServerName(), []string{ServerName()})
+	if err != nil {
+		return nil, err
+	}
+	keyPair, err := tls.X509KeyPair(cert.CrtPEM, cert.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := s.certMgr.GetRootCertificate()
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(root.CA) {
+		return nil, fmt.Errorf("invalid root CA")
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{keyPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		VerifyPeerCertificate: func(_ [][]byte, chains [][]*x509.Certificate) error {
+			for _, chain := range chains {
+				if len(chain) > 0 && chain[0].Subject.CommonName == ReporterName {
+					return nil
+				}
+			}
+			return fmt.Errorf("client certificate isn't issued to %s", ReporterName)
+		},
+	}, nil
+}
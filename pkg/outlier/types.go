@@ -0,0 +1,38 @@
+/*
+ * Copyright 2022 The flomesh.io Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+// ReportPath is the HTTP path which ingress-pipy posts failure reports to
+const ReportPath = "/api/v1/outliers"
+
+// Reports is the request body of failure reports
+type Reports struct {
+	Reports []Report `json:"reports"`
+}
+
+// Report is a target which fails in the last report interval, targets without failures aren't reported
+type Report struct {
+	// Target is the address of target in ip:port format, it's the gateway of an exporting cluster
+	Target string `json:"target"`
+
+	// Service is the ServiceImport in namespace/name[:port] format which the target is an endpoint of,
+	// reports without it are ignored
+	Service string `json:"service"`
+
+	// ConsecutiveFailures is the number of failures of the target since the last success
+	ConsecutiveFailures int32 `json:"consecutiveFailures"`
+}
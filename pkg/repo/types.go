@@ -51,7 +51,19 @@ type BatchItem struct {
 }
 
 type ServiceRegistry struct {
-	Services ServiceRegistryEntry `json:"services"`
+	Services ServiceRegistryEntry   `json:"services"`
+	Weights  ServiceRegistryWeights `json:"weights,omitempty"`
+	VIPs     ServiceRegistryVIPs    `json:"vips,omitempty"`
+	Hosts    ServiceRegistryHosts   `json:"hosts,omitempty"`
 }
 
 type ServiceRegistryEntry map[string][]string
+
+// ServiceRegistryWeights is the weight of each address, only for the services which have weighted targets
+type ServiceRegistryWeights map[string]map[string]int
+
+// ServiceRegistryVIPs maps the virtual IP:port of ServiceImport to the service name
+type ServiceRegistryVIPs map[string]string
+
+// ServiceRegistryHosts is the host header of requests to the service, ONLY for the Pods of headless ServiceImport
+type ServiceRegistryHosts map[string]string
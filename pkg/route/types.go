@@ -37,6 +37,57 @@ type IngressData struct {
 	Hash string `json:"hash" hash:"ignore"`
 	// Routes
 	Routes []IngressRouteSpec `json:"routes" hash:"set"`
+	// L4Routes, the TCP/UDP proxies of gateway ports of ServiceExports in L4 mode
+	L4Routes []L4RouteSpec `json:"l4Routes" hash:"set"`
+	// GatewayTLS, the mTLS listener which accepts traffic from other clusters
+	GatewayTLS *GatewayTLSSpec `json:"gatewayTLS,omitempty"`
+	// OutlierDetection, where failures of upstream targets are reported to
+	OutlierDetection *OutlierDetectionSpec `json:"outlierDetection,omitempty"`
+	// Egress, the listener which proxies traffic of imported services to the gateways of other clusters
+	Egress *EgressSpec `json:"egress,omitempty"`
+}
+
+type EgressSpec struct {
+	// Listen, the port which ingress-pipy accepts traffic of imported services on
+	Listen int32 `json:"listen"`
+	// Certificate, the gateway certificate of this cluster, it's presented to the gateways of other clusters
+	Certificate *CertificateSpec `json:"certificate,omitempty"`
+	// Hosts, the imported service which a request is routed to by its Host header
+	Hosts map[string]string `json:"hosts"`
+	// Services, the targets of each imported service
+	Services map[string][]EgressTarget `json:"services"`
+	// Imports, the ServiceImport in namespace/name format of each imported service, failures of targets
+	// are reported with it
+	Imports map[string]string `json:"imports"`
+}
+
+type EgressTarget struct {
+	// Address, the address of gateway of other cluster in host:port format, or the Pod in this cluster
+	Address string `json:"address"`
+	// Path, the path which the gateway of other cluster routes the service by
+	Path string `json:"path,omitempty"`
+	// TLS, the gateway of other cluster only accepts mTLS traffic
+	TLS *TargetTLS `json:"tls,omitempty"`
+	// Weight, the relative weight of the target, it's set only if the targets of the service are weighted
+	Weight int `json:"weight,omitempty"`
+}
+
+type OutlierDetectionSpec struct {
+	// Report, the URL of manager which failures are posted to
+	Report string `json:"report"`
+	// Interval, the interval in seconds of reporting failures
+	Interval int32 `json:"interval"`
+	// Certificate, the client certificate which ingress-pipy reports failures with, the manager is verified
+	// against its CA
+	Certificate *CertificateSpec `json:"certificate,omitempty"`
+}
+
+type GatewayTLSSpec struct {
+	// Listen, the port which ingress-pipy accepts mTLS traffic from other clusters on
+	Listen int32 `json:"listen"`
+	// Certificate, the gateway certificate issued by control plane, the client certificates
+	// of other clusters are verified against its CA
+	Certificate CertificateSpec `json:"certificate"`
 }
 
 type IngressRouteSpec struct {
@@ -45,6 +96,15 @@ type IngressRouteSpec struct {
 	TLSSpec      `json:",inline"`
 }
 
+type L4RouteSpec struct {
+	// Port, the gateway port which ingress-pipy listens on
+	Port     int32  `json:"-"`
+	Protocol string `json:"protocol"`
+	// Service, the service port which the traffic is proxied to
+	Service      string `json:"service"`
+	BalancerSpec `json:",inline"`
+}
+
 type RouterSpec struct {
 	Host    string   `json:"-"`
 	Path    string   `json:"-"`
@@ -96,6 +156,8 @@ type ServiceRoute struct {
 	// Hash
 	Hash   string              `json:"hash" hash:"ignore"`
 	Routes []ServiceRouteEntry `json:"routes" hash:"set"`
+	// Egress, the address of egress of ingress-pipy, targets which only accept mTLS are reached through it
+	Egress string `json:"egress,omitempty"`
 }
 
 type ServiceRouteEntry struct {
@@ -107,6 +169,11 @@ type ServiceRouteEntry struct {
 	Targets []Target `json:"targets" hash:"set"`
 	// PortName
 	PortName string `json:"portName,omitempty"`
+	// VIP, the virtual IP:port of ServiceImport, it's empty for local services
+	VIP string `json:"vip,omitempty"`
+	// Host, the host header of requests, ONLY for a single Pod of headless ServiceImport,
+	// the gateway of exporting cluster routes requests to the Pod by it
+	Host string `json:"host,omitempty"`
 }
 
 type Target struct {
@@ -114,13 +181,31 @@ type Target struct {
 	Address string `json:"address"`
 	// Tag, reserved placeholder for futher features
 	Tags map[string]string `json:"tags,omitempty" hash:"set"`
+	// TLS, the gateway of other cluster only accepts mTLS traffic
+	TLS *TargetTLS `json:"tls,omitempty"`
+}
+
+type TargetTLS struct {
+	// SNI, the server name of gateway, the certificate of gateway is verified against it
+	SNI string `json:"sni"`
+	// CA, which issues the certificate of gateway
+	CA string `json:"ca"`
 }
 
 type IngressConfig struct {
-	TrustedCAs     []string `json:"trustedCAs"`
-	TLSConfig      `json:",inline"`
-	RouterConfig   `json:",inline"`
-	BalancerConfig `json:",inline"`
+	TrustedCAs       []string `json:"trustedCAs"`
+	TLSConfig        `json:",inline"`
+	RouterConfig     `json:",inline"`
+	BalancerConfig   `json:",inline"`
+	L4Config         `json:",inline"`
+	GatewayTLS       *GatewayTLSSpec       `json:"gatewayTLS,omitempty"`
+	OutlierDetection *OutlierDetectionSpec `json:"outlierDetection,omitempty"`
+	Egress           *EgressSpec           `json:"egress,omitempty"`
+}
+
+type L4Config struct {
+	// L4, the key is the gateway port
+	L4 map[string]L4RouteSpec `json:"l4"`
 }
 
 type TLSConfig struct {
@@ -29,9 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
-	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
-	"net"
 )
 
 const (
@@ -123,11 +121,21 @@ func (w *ClusterDefaulter) SetDefaults(obj interface{}) {
 		c.Labels[commons.MultiClustersConnectorMode] = "remote"
 	}
 
+	if ref := c.Spec.KubeconfigSecretRef; ref != nil {
+		if ref.Namespace == "" {
+			ref.Namespace = config.GetErieCanalNamespace()
+		}
+		if ref.Key == "" {
+			ref.Key = commons.DefaultKubeconfigSecretKey
+		}
+	}
+
 	klog.V(4).Infof("After setting default values, spec=%#v", c.Spec)
 }
 
 type ClusterValidator struct {
-	k8sAPI *kube.K8sAPI
+	k8sAPI      *kube.K8sAPI
+	configStore *config.Store
 }
 
 func (w *ClusterValidator) RuntimeObject() runtime.Object {
@@ -164,6 +172,10 @@ func (w *ClusterValidator) ValidateCreate(obj interface{}) error {
 		}
 	}
 
+	if cluster.Spec.Kubeconfig != "" && w.kubeconfigSecretOnly() {
+		return errors.New("raw kubeconfig is not allowed, please store it in a Secret and reference it by spec.kubeconfigSecretRef")
+	}
+
 	return doValidation(obj)
 }
 
@@ -182,6 +194,11 @@ func (w *ClusterValidator) ValidateUpdate(oldObj, obj interface{}) error {
 		return errors.New("cannot update an immutable field: spec.IsInCluster")
 	}
 
+	// existing Clusters with raw kubeconfig are tolerated, but it can't be changed anymore
+	if cluster.Spec.Kubeconfig != "" && cluster.Spec.Kubeconfig != oldCluster.Spec.Kubeconfig && w.kubeconfigSecretOnly() {
+		return errors.New("raw kubeconfig is not allowed, please store it in a Secret and reference it by spec.kubeconfigSecretRef")
+	}
+
 	return doValidation(obj)
 }
 
@@ -189,9 +206,16 @@ func (w *ClusterValidator) ValidateDelete(obj interface{}) error {
 	return nil
 }
 
-func NewValidator(k8sAPI *kube.K8sAPI) *ClusterValidator {
+func (w *ClusterValidator) kubeconfigSecretOnly() bool {
+	mc := w.configStore.MeshConfig.GetConfig()
+
+	return mc != nil && mc.ClusterSet.KubeconfigSecretOnly
+}
+
+func NewValidator(k8sAPI *kube.K8sAPI, configStore *config.Store) *ClusterValidator {
 	return &ClusterValidator{
-		k8sAPI: k8sAPI,
+		k8sAPI:      k8sAPI,
+		configStore: configStore,
 	}
 }
 
@@ -229,45 +253,29 @@ func doValidation(obj interface{}) error {
 			return errors.New("GatewayHost is required in OutCluster mode")
 		}
 
-		if c.Spec.Kubeconfig == "" {
-			return fmt.Errorf("kubeconfig must be set in OutCluster mode")
+		ref := c.Spec.KubeconfigSecretRef
+		if c.Spec.Kubeconfig == "" && ref == nil {
+			return fmt.Errorf("either kubeconfig or kubeconfigSecretRef must be set in OutCluster mode")
 		}
 
-		//if c.Name == "local" {
-		//	return errors.New("Cluster Name 'local' is reserved for InCluster Mode ONLY, please change the cluster name")
-		//}
-
-		isDNSName := false
-		if ipErrs := validation.IsValidIPv4Address(field.NewPath(""), host); len(ipErrs) > 0 {
-			// Not IPv4 address
-			klog.Warningf("%q is NOT a valid IPv4 address: %v", host, ipErrs)
-			if dnsErrs := validation.IsDNS1123Subdomain(host); len(dnsErrs) > 0 {
-				// Not valid DNS domain name
-				return fmt.Errorf("invalid DNS name %q: %v", host, dnsErrs)
-			} else {
-				// is DNS name
-				isDNSName = true
-			}
+		if c.Spec.Kubeconfig != "" && ref != nil {
+			return fmt.Errorf("kubeconfig and kubeconfigSecretRef are mutually exclusive")
 		}
 
-		var gwIPv4 net.IP
-		if isDNSName {
-			ipAddr, err := net.ResolveIPAddr("ip4", host)
-			if err != nil {
-				return fmt.Errorf("%q cannot be resolved to IP", host)
-			}
-			klog.Infof("%q is resolved to IP: %s", host, ipAddr.IP)
-			gwIPv4 = ipAddr.IP.To4()
-		} else {
-			gwIPv4 = net.ParseIP(host).To4()
+		if ref != nil && ref.Name == "" {
+			return fmt.Errorf("name of kubeconfigSecretRef is required")
 		}
 
-		if gwIPv4 == nil {
-			return fmt.Errorf("%q cannot be resolved to a IPv4 address", host)
+		if ref != nil && ref.Namespace != "" && ref.Namespace != config.GetErieCanalNamespace() {
+			return fmt.Errorf("kubeconfigSecretRef must reference a Secret in namespace %s", config.GetErieCanalNamespace())
 		}
 
-		if gwIPv4 != nil && (gwIPv4.IsLoopback() || gwIPv4.IsUnspecified()) {
-			return fmt.Errorf("gateway Host %s is resolved to Loopback IP or Unspecified", host)
+		//if c.Name == "local" {
+		//	return errors.New("Cluster Name 'local' is reserved for InCluster Mode ONLY, please change the cluster name")
+		//}
+
+		if _, err := config.ResolveGatewayIPs(host); err != nil {
+			return err
 		}
 
 		port := int(c.Spec.GatewayPort)
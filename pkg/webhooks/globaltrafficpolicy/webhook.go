@@ -142,6 +142,11 @@ func (w *GlobalTrafficPolicyValidator) doValidation(obj interface{}) error {
 		return nil
 	}
 
+	if policy.Spec.LocalityThresholds != nil &&
+		(policy.Spec.LbType != gtpv1alpha1.LocalityLbType || len(policy.Spec.Targets) > 0) {
+		return fmt.Errorf("localityThresholds takes effect only in case of Locality load balancer without targets")
+	}
+
 	switch policy.Spec.LbType {
 	case gtpv1alpha1.LocalityLbType:
 		if len(policy.Spec.Targets) > 1 {
@@ -17,6 +17,7 @@
 package serviceexport
 
 import (
+	"fmt"
 	svcexpv1alpha1 "github.com/flomesh-io/ErieCanal/apis/serviceexport/v1alpha1"
 	flomeshadmission "github.com/flomesh-io/ErieCanal/pkg/admission"
 	"github.com/flomesh-io/ErieCanal/pkg/commons"
@@ -105,7 +106,8 @@ func (w *ServiceExportDefaulter) SetDefaults(obj interface{}) {
 }
 
 type ServiceExportValidator struct {
-	k8sAPI *kube.K8sAPI
+	k8sAPI      *kube.K8sAPI
+	configStore *config.Store
 }
 
 func (w *ServiceExportValidator) RuntimeObject() runtime.Object {
@@ -113,28 +115,67 @@ func (w *ServiceExportValidator) RuntimeObject() runtime.Object {
 }
 
 func (w *ServiceExportValidator) ValidateCreate(obj interface{}) error {
-	return doValidation(obj)
+	return w.doValidation(obj)
 }
 
 func (w *ServiceExportValidator) ValidateUpdate(oldObj, obj interface{}) error {
-	return doValidation(obj)
+	return w.doValidation(obj)
 }
 
 func (w *ServiceExportValidator) ValidateDelete(obj interface{}) error {
 	return nil
 }
 
-func NewValidator(k8sAPI *kube.K8sAPI) *ServiceExportValidator {
+func NewValidator(k8sAPI *kube.K8sAPI, configStore *config.Store) *ServiceExportValidator {
 	return &ServiceExportValidator{
-		k8sAPI: k8sAPI,
+		k8sAPI:      k8sAPI,
+		configStore: configStore,
 	}
 }
 
-func doValidation(obj interface{}) error {
-	//serviceExport, ok := obj.(*svcexpv1alpha1.ServiceExport)
-	//if !ok {
-	//    return nil
-	//}
+func (w *ServiceExportValidator) doValidation(obj interface{}) error {
+	serviceExport, ok := obj.(*svcexpv1alpha1.ServiceExport)
+	if !ok {
+		return nil
+	}
+
+	mc := w.configStore.MeshConfig.GetConfig()
+	if !mc.IsNamespaceExportable(serviceExport.Namespace) {
+		return fmt.Errorf("namespace %s is not allowed to export services, it's not in clusterSet.namespaces.exports of MeshConfig", serviceExport.Namespace)
+	}
+
+	for _, target := range serviceExport.Spec.TargetClusters {
+		if err := svcexpv1alpha1.ValidateTargetCluster(target); err != nil {
+			return err
+		}
+	}
+
+	if serviceExport.IsL4() {
+		return validateL4Rules(serviceExport)
+	}
+
+	for _, rule := range serviceExport.Spec.Rules {
+		if rule.Path == "" || rule.PathType == nil {
+			return fmt.Errorf("path and pathType of rule for port %d are required in HTTP mode", rule.PortNumber)
+		}
+	}
+
+	return nil
+}
+
+func validateL4Rules(serviceExport *svcexpv1alpha1.ServiceExport) error {
+	ports := make(map[string]bool)
+	for _, rule := range serviceExport.Spec.Rules {
+		if rule.PortNumber <= 0 {
+			return fmt.Errorf("portNumber of rule is required in L4 mode")
+		}
+
+		key := fmt.Sprintf("%d/%s", rule.PortNumber, rule.GetProtocol())
+		if ports[key] {
+			return fmt.Errorf("port %s is duplicated", key)
+		}
+		ports[key] = true
+	}
 
 	return nil
 }